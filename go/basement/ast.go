@@ -1,5 +1,11 @@
 package basement
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // NodeType identifies the type of a node in the AST
 type NodeType int
 
@@ -7,7 +13,7 @@ const (
 	NodeRoot NodeType = iota
 	NodeText
 	NodeStyle
-	NodeHole      // Represents a %v placeholder
+	NodeHole      // Represents a %v or %{name} placeholder
 	NodeBlock     // Generic block element (paragraph)
 	NodeHeader    // Header element (#)
 	NodeList      // List container
@@ -15,16 +21,64 @@ const (
 	NodeCodeBlock // Code block (```)
 	NodeHR        // Horizontal Rule (---)
 	NodeQuote     // Blockquote (>)
+	NodeImage     // Image (![alt](url)); Content holds the alt text
+	NodeLink      // Link ([text](url)); Children hold the parsed text
+	NodeAbbr      // Defined abbreviation; Content holds its expansion
+)
+
+var nodeTypeNames = [...]string{
+	NodeRoot:      "Root",
+	NodeText:      "Text",
+	NodeStyle:     "Style",
+	NodeHole:      "Hole",
+	NodeBlock:     "Block",
+	NodeHeader:    "Header",
+	NodeList:      "List",
+	NodeListItem:  "ListItem",
+	NodeCodeBlock: "CodeBlock",
+	NodeHR:        "HR",
+	NodeQuote:     "Quote",
+	NodeImage:     "Image",
+	NodeLink:      "Link",
+	NodeAbbr:      "Abbr",
+}
+
+func (t NodeType) String() string {
+	if t >= 0 && int(t) < len(nodeTypeNames) {
+		return nodeTypeNames[t]
+	}
+	return fmt.Sprintf("NodeType(%d)", int(t))
+}
+
+// RuleStyle is the line style of a horizontal rule, chosen by its marker
+type RuleStyle int
+
+const (
+	RuleLight  RuleStyle = iota // ---
+	RuleHeavy                   // ***
+	RuleDouble                  // ___
 )
 
 // Node represents a node in the AST
 type Node struct {
 	Type     NodeType
-	Content  string      // For text nodes or code blocks
-	Lang     string      // For code blocks (language identifier)
-	Style    Style       // For styled nodes
-	Children []*Node     // For nested nodes
-	HoleID   int         // Index of the argument for this hole (0-based)
+	Content  string            // For text nodes or code blocks
+	Lang     string            // For code blocks (language identifier)
+	Style    Style             // For styled nodes
+	Children []*Node           // For nested nodes
+	HoleID   int               // Index of the argument for this hole (0-based)
+	HoleName string            // Name of a %{name} hole; empty for positional %v holes
+	Depth    int               // Nesting level for blockquotes (1 for a single >)
+	URL      string            // Target of a link or image
+	Ref      string            // Reference label of [text][id] until it is resolved
+	Rule     RuleStyle         // Line style of a horizontal rule
+	Attrs    map[string]string // Attributes of a code block's fence, e.g. highlight=2
+	Line     int               // 1-based source line (a code block's opening fence); 0 for the root
+	Task     bool              // List item written as a task, "- [ ] todo" or "- [x] done"
+	Checked  bool              // Whether a task list item is done
+	Code     bool              // Inline `code` span; its text is literal
+
+	source string // Link as written, restored if its reference is undefined
 }
 
 // NewNode creates a new node
@@ -39,3 +93,103 @@ func NewNode(typ NodeType) *Node {
 func (n *Node) AddChild(child *Node) {
 	n.Children = append(n.Children, child)
 }
+
+// DumpAST returns the tree under n as text, one node per line indented by
+// depth: its type, then whichever fields are set. Handy for seeing why a
+// template renders the way it does:
+//
+//	Root
+//	  Header line=1 style=bold,reverse
+//	    Text "Hi " line=1
+//	    Style line=1 style=bold
+//	      Text "x" line=1
+func DumpAST(n *Node) string {
+	var b strings.Builder
+	dumpNode(&b, n, 0)
+	return b.String()
+}
+
+func dumpNode(b *strings.Builder, n *Node, depth int) {
+	if n == nil {
+		return
+	}
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(n.Type.String())
+	if n.Content != "" {
+		fmt.Fprintf(b, " %q", n.Content)
+	}
+	if n.Type == NodeHole {
+		if n.HoleName != "" {
+			fmt.Fprintf(b, " name=%s", n.HoleName)
+		}
+		// IDs are -1 until a template numbers its holes
+		if n.HoleID >= 0 {
+			fmt.Fprintf(b, " id=%d", n.HoleID)
+		}
+	}
+	if n.Line > 0 {
+		fmt.Fprintf(b, " line=%d", n.Line)
+	}
+	if n.Lang != "" {
+		fmt.Fprintf(b, " lang=%s", n.Lang)
+	}
+	if n.Depth > 0 {
+		fmt.Fprintf(b, " depth=%d", n.Depth)
+	}
+	if n.Type == NodeHR {
+		fmt.Fprintf(b, " rule=%s", [...]string{"light", "heavy", "double"}[n.Rule])
+	}
+	if n.URL != "" {
+		fmt.Fprintf(b, " url=%q", n.URL)
+	}
+	if n.Ref != "" {
+		fmt.Fprintf(b, " ref=%q", n.Ref)
+	}
+	if n.Task {
+		fmt.Fprintf(b, " checked=%v", n.Checked)
+	}
+	if n.Code {
+		b.WriteString(" code")
+	}
+	if len(n.Attrs) > 0 {
+		keys := make([]string, 0, len(n.Attrs))
+		for k := range n.Attrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(b, " %s=%q", k, n.Attrs[k])
+		}
+	}
+	if n.Style != (Style{}) {
+		b.WriteString(" style=")
+		b.WriteString(styleString(n.Style))
+	}
+	b.WriteByte('\n')
+	for _, child := range n.Children {
+		dumpNode(b, child, depth+1)
+	}
+}
+
+// styleString lists the attributes set in s, e.g. bold,dim,color="\x1b[31m"
+func styleString(s Style) string {
+	var parts []string
+	for _, flag := range []struct {
+		on   bool
+		name string
+	}{
+		{s.Bold, "bold"}, {s.Dim, "dim"}, {s.Italic, "italic"}, {s.Underline, "underline"},
+		{s.Strike, "strike"}, {s.Reverse, "reverse"}, {s.Blink, "blink"}, {s.Hidden, "hidden"},
+	} {
+		if flag.on {
+			parts = append(parts, flag.name)
+		}
+	}
+	if s.Color != "" {
+		parts = append(parts, fmt.Sprintf("color=%q", s.Color))
+	}
+	if s.BgColor != "" {
+		parts = append(parts, fmt.Sprintf("bg=%q", s.BgColor))
+	}
+	return strings.Join(parts, ",")
+}
@@ -3,8 +3,8 @@ package basement
 import (
 	"crypto/md5"
 	"encoding/base64"
-	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -14,62 +14,91 @@ var (
 	headerRe      = regexp.MustCompile("(?m)^(\\#{1,6})[ \\t]+(.+?)[ \\t]*\\#*([\r\n]+|$)")
 	listRe        = regexp.MustCompile("(?m)^([ \\t]{1,})[*+-]([ \\t]{1,})")
 	quoteRe       = regexp.MustCompile("(?m)^[ \\t]*>([ \\t]?)")
-	colorRe       = regexp.MustCompile("(?s)(!?)#([a-zA-Z0-9]{3,8})\\((.+?)\\)([^)]|$)")
+	colorRe       = regexp.MustCompile("(?s)(!?)#([a-zA-Z0-9]{3,16})\\((.+?)\\)([^)]|$)")
+	escapeRe      = regexp.MustCompile("\\\\([!-/:-@\\[-`{-~])")
 
-	// Precomputed regexes for boldUnderlineStrike
-	styleRegexes []*regexp.Regexp
-	styleReplacements []struct{ start, end string }
-)
 
-func init() {
-	styles := []struct {
-		char      string
-		codeStart string
-		codeEnd   string
+	// Inline styles applied by boldUnderlineStrike, in order. Each wraps
+	// text between single or doubled delimiters: *bold* or **bold**.
+	inlineStyles = []struct {
+		char       byte
+		start, end string
 	}{
-		{"\\*", "\x1b[1m", "\x1b[22m"}, // Bold
-		{"-", "\x1b[2m", "\x1b[22m"},   // Dim
-		{"_", "\x1b[4m", "\x1b[24m"},   // Underline
-		{":", "\x1b[5m", "\x1b[25m"},   // Blink
-		{"!", "\x1b[7m", "\x1b[27m"},   // Reverse
-		{"\\?", "\x1b[8m", "\x1b[28m"}, // Hidden
-		{"~", "\x1b[9m", "\x1b[29m"},   // Strike
-	}
-
-	for _, style := range styles {
-		c := style.char
-		pattern := fmt.Sprintf(
-			"(?s)(%s%s)(\\S|\\S.*?\\S)%s%s|(%s)(\\S|\\S.*?\\S)%s",
-			c, c, c, c, c, c,
-		)
-		styleRegexes = append(styleRegexes, regexp.MustCompile(pattern))
-		styleReplacements = append(styleReplacements, struct{ start, end string }{style.codeStart, style.codeEnd})
+		{'*', "\x1b[1m", "\x1b[22m"}, // Bold
+		{'-', "\x1b[2m", "\x1b[22m"}, // Dim
+		{'_', "\x1b[4m", "\x1b[24m"}, // Underline
+		{':', "\x1b[5m", "\x1b[25m"}, // Blink
+		{'!', "\x1b[7m", "\x1b[27m"}, // Reverse
+		{'?', "\x1b[8m", "\x1b[28m"}, // Hidden
+		{'~', "\x1b[9m", "\x1b[29m"}, // Strike
 	}
-}
+)
 
 // Parse takes a basement formatted string and returns the ANSI escaped string
 func Parse(txt string) string {
+	return parse(txt, true)
+}
+
+// ParsePlain runs the same parsing as Parse but drops all styling, returning
+// clean text (bullets and rules kept, escapes and colors removed).
+// Useful for logging or writing to files.
+func ParsePlain(txt string) string {
+	return parse(txt, false)
+}
+
+// ParseAuto is Parse when colorEnabled is set and ParsePlain otherwise.
+func ParseAuto(txt string, colorEnabled bool) string {
+	return parse(txt, colorEnabled)
+}
+
+func parse(txt string, ansi bool) string {
+	txt = normalizeNewlines(txt)
+	in := txt
+
 	// Local map to ensure thread safety
 	codeMap := make(map[string]string)
 
 	// Preserve code blocks
 	txt = processCodeBlocks(txt, codeMap)
 
-	txt = horizontal(txt)
-	txt = header(txt)
-	txt = boldUnderlineStrike(txt)
+	// Hide escaped characters from the style regexes
+	escapes := make(map[string]string)
+	txt = hideEscapes(txt, escapes)
+
+	txt = horizontal(txt, ansi)
+	txt = header(txt, ansi)
+	txt = boldUnderlineStrike(txt, ansi)
 	txt = list(txt)
-	txt = quote(txt)
-	txt = color(txt)
+	txt = quote(txt, ansi)
+	txt = color(txt, ansi)
+
+	txt = restoreEscapes(txt, escapes)
 
 	// Restore code blocks
 	for hash, content := range codeMap {
 		txt = strings.ReplaceAll(txt, hash, content)
 	}
 
+	// The output ends in a newline exactly when the input does, whatever the
+	// last line turned into
+	if strings.HasSuffix(in, "\n") && !strings.HasSuffix(txt, "\n") {
+		txt += "\n"
+	} else if !strings.HasSuffix(in, "\n") {
+		txt = strings.TrimSuffix(txt, "\n")
+	}
+
 	return txt
 }
 
+// normalizeNewlines turns Windows (\r\n) and old Mac (\r) line endings into \n
+func normalizeNewlines(s string) string {
+	if strings.IndexByte(s, '\r') < 0 {
+		return s
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\r", "\n")
+}
+
 type replacement struct {
 	start, end int
 	text       string
@@ -145,23 +174,57 @@ func processCodeBlocks(txt string, codeMap map[string]string) string {
 	return sb.String()
 }
 
+// hideEscapes turns each \X (X being ASCII punctuation) into a placeholder
+// that no markup regex matches: a private use rune absent from txt, the
+// index of the escape, then the rune again. The placeholders map back to the
+// literal characters in escapes.
+func hideEscapes(txt string, escapes map[string]string) string {
+	sentinel := rune(0xE000)
+	for strings.ContainsRune(txt, sentinel) {
+		sentinel++
+	}
+	return escapeRe.ReplaceAllStringFunc(txt, func(m string) string {
+		placeholder := string(sentinel) + strconv.Itoa(len(escapes)) + string(sentinel)
+		escapes[placeholder] = m[1:]
+		return placeholder
+	})
+}
+
+// restoreEscapes turns placeholders back into the literal characters.
+func restoreEscapes(txt string, escapes map[string]string) string {
+	if len(escapes) == 0 {
+		return txt
+	}
+	pairs := make([]string, 0, 2*len(escapes))
+	for placeholder, char := range escapes {
+		pairs = append(pairs, placeholder, char)
+	}
+	return strings.NewReplacer(pairs...).Replace(txt)
+}
+
 func md5Base64(text string) string {
 	hash := md5.Sum([]byte(text))
 	return base64.StdEncoding.EncodeToString(hash[:])
 }
 
-func horizontal(txt string) string {
+func horizontal(txt string, ansi bool) string {
 	line := strings.Repeat("─", 72)
+	if !ansi {
+		return horizontalRe.ReplaceAllLiteralString(txt, line)
+	}
 	return horizontalRe.ReplaceAllString(txt, "\x1b[1m"+line+"\x1b[22m")
 }
 
-func header(txt string) string {
+func header(txt string, ansi bool) string {
 	return headerRe.ReplaceAllStringFunc(txt, func(match string) string {
 		parts := headerRe.FindStringSubmatch(match)
 		hash := parts[1]
 		content := parts[2]
 		suffix := parts[3]
 
+		if !ansi {
+			return content + suffix
+		}
 		if len(hash) == 1 {
 			content = "\x1b[1m" + content + "\x1b[22m"
 		}
@@ -169,29 +232,90 @@ func header(txt string) string {
 	})
 }
 
-func boldUnderlineStrike(txt string) string {
-	for i, re := range styleRegexes {
-		style := styleReplacements[i]
-		txt = re.ReplaceAllStringFunc(txt, func(m string) string {
-			sub := re.FindStringSubmatch(m)
-			if sub[1] != "" {
-				return style.start + sub[2] + style.end
-			}
-			return style.start + sub[4] + style.end
-		})
+func boldUnderlineStrike(txt string, ansi bool) string {
+	for _, style := range inlineStyles {
+		if !ansi {
+			style.start, style.end = "", ""
+		}
+		txt = styleSpans(txt, style.char, style.start, style.end)
 	}
 	return txt
 }
 
+// styleSpans wraps every c-delimited span of txt in start/end. A span is cc or
+// c, then text that neither starts nor ends with whitespace, then the same
+// delimiter; the nearest closer wins and doubled delimiters are tried first.
+// It runs in linear time: the nearest closer after every position is found
+// in one backwards pass, so unclosed delimiters can't cause rescanning.
+func styleSpans(txt string, c byte, start, end string) string {
+	if strings.IndexByte(txt, c) < 0 {
+		return txt
+	}
+
+	// nextDouble[i], nextSingle[i]: smallest q >= i where a doubled or single
+	// closer starts (txt[q] is c, so is txt[q+1] if doubled, and txt[q-1]
+	// isn't whitespace), or -1
+	n := len(txt)
+	nextDouble := make([]int, n+2)
+	nextSingle := make([]int, n+2)
+	nextDouble[n], nextDouble[n+1] = -1, -1
+	nextSingle[n], nextSingle[n+1] = -1, -1
+	for q := n - 1; q >= 0; q-- {
+		nextDouble[q], nextSingle[q] = nextDouble[q+1], nextSingle[q+1]
+		if txt[q] != c || q == 0 || isSpace(txt[q-1]) {
+			continue
+		}
+		nextSingle[q] = q
+		if q+1 < n && txt[q+1] == c {
+			nextDouble[q] = q
+		}
+	}
+
+	var b strings.Builder
+	last := 0
+	for p := 0; p < n; p++ {
+		if txt[p] != c {
+			continue
+		}
+		// Doubled: cc, non-space, ..., non-space, cc
+		if p+2 < n && txt[p+1] == c && !isSpace(txt[p+2]) {
+			if q := nextDouble[p+3]; q >= 0 {
+				b.WriteString(txt[last:p])
+				b.WriteString(start + txt[p+2:q] + end)
+				last = q + 2
+				p = last - 1
+				continue
+			}
+		}
+		// Single: c, non-space, ..., non-space, c
+		if p+1 < n && !isSpace(txt[p+1]) {
+			if q := nextSingle[p+2]; q >= 0 {
+				b.WriteString(txt[last:p])
+				b.WriteString(start + txt[p+1:q] + end)
+				last = q + 1
+				p = last - 1
+			}
+		}
+	}
+	if last == 0 {
+		return txt
+	}
+	b.WriteString(txt[last:])
+	return b.String()
+}
+
 func list(txt string) string {
 	return listRe.ReplaceAllString(txt, "$1•$2")
 }
 
-func quote(txt string) string {
+func quote(txt string, ansi bool) string {
+	if !ansi {
+		return quoteRe.ReplaceAllString(txt, "│$1")
+	}
 	return quoteRe.ReplaceAllString(txt, "\x1b[7m$1\x1b[27m$1")
 }
 
-func color(txt string) string {
+func color(txt string, ansi bool) string {
 	return colorRe.ReplaceAllStringFunc(txt, func(match string) string {
 		parts := colorRe.FindStringSubmatch(match)
 		bg := parts[1]
@@ -199,21 +323,22 @@ func color(txt string) string {
 		content := parts[3]
 		suffix := parts[4]
 
+		if !ansi {
+			return content + suffix
+		}
 		return getColor(bg, rgb, content) + suffix
 	})
 }
 
 func getColor(bg, rgb, txt string) string {
-	out := GetColorCode(rgb)
-
-	if out == "" {
-		out = txt
-	} else {
-		out = out + txt + "\x1b[39m"
+	if bg != "" {
+		if code := GetBgColorCode(rgb); code != "" {
+			return code + txt + "\x1b[49m"
+		}
+		return txt
 	}
-
-	if bg == "" {
-		return out
+	if code := GetColorCode(rgb); code != "" {
+		return code + txt + "\x1b[39m"
 	}
-	return "\x1b[7m" + out + "\x1b[27m"
+	return txt
 }
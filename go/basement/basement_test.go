@@ -0,0 +1,247 @@
+package basement
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePlain(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"header", "# Title\n## Sub", "Title\nSub"},
+		{"list", "  * one\n  - two", "  • one\n  • two"},
+		{"color", "say #green(hi) and !#red(bg)", "say hi and bg"},
+		{"emphasis", "**bold** and __under__", "bold and under"},
+		{"quote", "> quoted", "│ quoted"},
+		{"code", "`#red(raw)`", "`#red(raw)`"},
+	}
+
+	for _, tt := range tests {
+		got := ParsePlain(tt.input)
+		if got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+		if strings.Contains(got, "\x1b") {
+			t.Errorf("%s: output contains escape codes: %q", tt.name, got)
+		}
+	}
+}
+
+func TestParseKeepsTrailingNewline(t *testing.T) {
+	for _, in := range []string{"plain", "# header", "---", "> quote", "* item", "**bold**", "```\ncode\n```", "a\n\n"} {
+		for _, s := range []string{in, in + "\n"} {
+			for _, out := range []string{Parse(s), ParsePlain(s)} {
+				if strings.HasSuffix(out, "\n") != strings.HasSuffix(s, "\n") {
+					t.Errorf("%q: expected the trailing newline kept as is, got %q", s, out)
+				}
+			}
+		}
+	}
+}
+
+func TestParseEscapes(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"asterisks", `\*not italic\*`, "*not italic*"},
+		{"double asterisks", `\*\*not bold\*\*`, "**not bold**"},
+		{"color", `\#red(plain)`, "#red(plain)"},
+		{"hole", `\%v`, "%v"},
+		{"header", `\# not a header`, "# not a header"},
+		{"code keeps backslash", "`\\*`", "`\\*`"},
+		{"private use input", "\ue02a \\* \ue000", "\ue02a * \ue000"},
+		{"private use before an index", "\ue000\\*\ue000", "\ue000*\ue000"},
+	}
+
+	for _, tt := range tests {
+		if got := Parse(tt.input); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+// restoreColor returns a func putting the registry entry for name back as it
+// is now, deleting it if there is none
+func restoreColor(name string) func() {
+	colorMu.RLock()
+	code, ok := colors[name]
+	colorMu.RUnlock()
+	return func() {
+		colorMu.Lock()
+		defer colorMu.Unlock()
+		if ok {
+			colors[name] = code
+		} else {
+			delete(colors, name)
+		}
+	}
+}
+
+func TestRegisterColor(t *testing.T) {
+	defer restoreColor("brand")()
+	RegisterColor("brand", "\x1b[38;5;208m")
+	if got := Parse("#brand(hi)"); !strings.Contains(got, "\x1b[38;5;208mhi") {
+		t.Errorf("Expected the registered color in the output, got %q", got)
+	}
+
+	root := ParseAST("#brand(hi)")
+	style := root.Children[0].Children[0]
+	if style.Type != NodeStyle || style.Style.Color != "\x1b[38;5;208m" {
+		t.Errorf("Expected a node styled with the registered color, got %+v", style)
+	}
+
+	// Built-in names can be overridden
+	defer restoreColor("grey")()
+	RegisterColor("grey", "\x1b[37m")
+	if got := GetColorCode("grey"); got != "\x1b[37m" {
+		t.Errorf("Expected grey to be overridden, got %q", got)
+	}
+
+	// Names markup can't refer to are refused
+	for _, name := range []string{"ab", "seventeenletters1", "my-brand", "brand!", "café"} {
+		if err := RegisterColor(name, "\x1b[31m"); err == nil {
+			t.Errorf("%q: expected an error", name)
+		}
+		if got := GetColorCode(name); got != "" {
+			t.Errorf("%q: expected nothing registered, got %q", name, got)
+		}
+	}
+}
+
+func TestColorNames(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"brightblack", "\x1b[90m"},
+		{"brightred", "\x1b[91m"},
+		{"brightgreen", "\x1b[92m"},
+		{"brightyellow", "\x1b[93m"},
+		{"brightblue", "\x1b[94m"},
+		{"brightmagenta", "\x1b[95m"},
+		{"brightcyan", "\x1b[96m"},
+		{"brightwhite", "\x1b[97m"},
+		{"gray", "\x1b[90m"},
+		{"purple", "\x1b[35m"},
+		{"default", "\x1b[39m"},
+		{"reset", "\x1b[39m"},
+		{"red", "\x1b[31m"},
+		{"nosuchcolor", ""},
+	}
+	for _, tt := range tests {
+		if got := GetColorCode(tt.name); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+
+	// Long names work in markup, and aliases follow overrides
+	if got := Parse("#brightmagenta(x)"); got != "\x1b[95mx\x1b[39m" {
+		t.Errorf("Expected #brightmagenta to color its text, got %q", got)
+	}
+	defer restoreColor("grey")()
+	RegisterColor("grey", "\x1b[37m")
+	if got := GetColorCode("gray"); got != "\x1b[37m" {
+		t.Errorf("Expected gray to follow grey, got %q", got)
+	}
+}
+
+func TestBackgroundColor(t *testing.T) {
+	if got := Parse("!#red(x)"); got != "\x1b[41mx\x1b[49m" {
+		t.Errorf("Expected a red background, got %q", got)
+	}
+	if got := ParseAST("!#red(x)").Children[0].Children[0].Style; got != (Style{BgColor: "\x1b[41m"}) {
+		t.Errorf("Expected the node's BgColor red, got %+v", got)
+	}
+
+	tests := []struct {
+		name, want string
+	}{
+		{"brightcyan", "\x1b[106m"},
+		{"default", "\x1b[49m"},
+		{"nosuchcolor", ""},
+	}
+	for _, tt := range tests {
+		if got := GetBgColorCode(tt.name); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+	defer restoreColor("brand")()
+	RegisterColor("brand", "\x1b[38;5;208m")
+	if got := GetBgColorCode("brand"); got != "\x1b[48;5;208m" {
+		t.Errorf("Expected a 256-color background, got %q", got)
+	}
+}
+
+// styleSpansReference applies the bold style with the regular expression
+// boldUnderlineStrike used before the linear scanner.
+var styleSpansRe = regexp.MustCompile(fmt.Sprintf("(?s)(%s%s)(\\S|\\S.*?\\S)%s%s|(%s)(\\S|\\S.*?\\S)%s", `\*`, `\*`, `\*`, `\*`, `\*`, `\*`))
+
+func styleSpansReference(txt string, start, end string) string {
+	re := styleSpansRe
+	return re.ReplaceAllStringFunc(txt, func(m string) string {
+		sub := re.FindStringSubmatch(m)
+		if sub[1] != "" {
+			return start + sub[2] + end
+		}
+		return start + sub[4] + end
+	})
+}
+
+func TestStyleSpansMatchesReference(t *testing.T) {
+	inputs := []string{
+		"*bold* **bold** * not * *a*b* **a*b** ***x***",
+		"a * b\n*multi\nline*",
+		"é*ü* *日本*",
+	}
+	alphabet := []string{"*", "*", " ", "a", "\n", "é"}
+	var gen func(prefix string, depth int)
+	gen = func(prefix string, depth int) {
+		inputs = append(inputs, prefix)
+		if depth == 0 {
+			return
+		}
+		for _, s := range alphabet {
+			gen(prefix+s, depth-1)
+		}
+	}
+	gen("", 7)
+
+	for _, in := range inputs {
+		want := styleSpansReference(in, "<", ">")
+		if got := styleSpans(in, '*', "<", ">"); got != want {
+			t.Fatalf("%q: expected %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestParseAdversarialInputIsFast(t *testing.T) {
+	var b strings.Builder
+	for b.Len() < 100*1024 {
+		b.WriteString("*_~ *a _b ~~c **")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		Parse(b.String())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Parse took too long on 100KB of delimiters")
+	}
+}
+
+func BenchmarkParseDelimiters(b *testing.B) {
+	in := strings.Repeat("*_~ *a _b ~~c **", 6*1024)
+	for i := 0; i < b.N; i++ {
+		Parse(in)
+	}
+}
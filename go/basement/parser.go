@@ -3,6 +3,9 @@ package basement
 import (
 	"regexp"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 )
 
 var (
@@ -10,47 +13,138 @@ var (
 	headerBlockRe = regexp.MustCompile(`^(\#{1,6})[ \t]+(.+)`)
 	hrBlockRe     = regexp.MustCompile(`^(\*{3,}|-{3,}|_{3,})$`)
 	listBlockRe   = regexp.MustCompile(`^([ \t]*)([*+-]|\d+\.)[ \t]+(.+)`)
-	quoteBlockRe  = regexp.MustCompile(`^>[ \t]*(.+)`)
-	codeFenceRe   = regexp.MustCompile(`^` + "```" + `(.*)`) // Capture language
+	taskItemRe    = regexp.MustCompile(`^\[([ xX])\](?:[ \t]+(.*))?$`)
+	quoteBlockRe  = regexp.MustCompile(`^((?:>[ \t]*)+)(.*)`) // One > per nesting level
+	codeFenceRe   = regexp.MustCompile("^(`{3,}|~{3,})(.*)") // Capture fence and language
+	autolinkRe    = regexp.MustCompile(`(?:^|[^\w])((?:https?://|www\.)[\p{L}\p{N}][^\s<>]*)`) // Not inside a word; at least one host character
+	refDefRe      = regexp.MustCompile(`^[ ]{0,3}\[([^\]^][^\]]*)\]:[ \t]*(\S+)(?:[ \t]+"[^"]*")?[ \t]*$`)
+	abbrDefRe     = regexp.MustCompile(`^[ ]{0,3}\*\[([^\]]+)\]:[ \t]*(.*?)[ \t]*$`)
+)
+
+// CodeStyle is applied to inline `code` spans
+var CodeStyle = Style{Reverse: true}
+
+// ScriptStyle is applied to ^super^ and ~sub~ scripts that have no Unicode
+// super- or subscript form
+var ScriptStyle = Style{Dim: true}
 
-	// Inline Regexes
-	inlineTokenRe = regexp.MustCompile(`(%v)|(\*\*.+?\*\*)|(__.+?__)|(!?#[a-zA-Z0-9]{3,8}\(.+?\))`)
+// AbbrStyle marks abbreviations defined with *[ABBR]: expansion
+var AbbrStyle = Style{Underline: true}
+
+// Heading styles by level, h1 first
+var (
+	headingMu            sync.RWMutex
+	defaultHeadingStyles = [6]Style{
+		{Bold: true, Reverse: true},
+		{Bold: true, Underline: true},
+		{Bold: true, Color: GetColorCode("cyan")},
+		{Bold: true},
+		{Bold: true, Dim: true},
+		{Dim: true},
+	}
+	headingStyles = defaultHeadingStyles
 )
 
+// HeaderTheme maps heading levels (1-6) to the style ParseAST gives them
+type HeaderTheme map[int]Style
+
+// SetHeaderTheme styles headings by level, e.g.
+// SetHeaderTheme(HeaderTheme{1: {Bold: true, Color: GetColorCode("cyan")}, 3: {Dim: true}}).
+// Levels the theme leaves out keep their default style; nil restores all the
+// defaults. Like SetHeadingStyles, call it at startup.
+func SetHeaderTheme(theme HeaderTheme) {
+	styles := defaultHeadingStyles
+	for level, style := range theme {
+		if level >= 1 && level <= 6 {
+			styles[level-1] = style
+		}
+	}
+	SetHeadingStyles(styles)
+}
+
+// SetHeadingStyles replaces the styles given to headings h1 to h6.
+// Call it at startup: tui caches parsed templates, which keep their styles.
+func SetHeadingStyles(styles [6]Style) {
+	headingMu.Lock()
+	defer headingMu.Unlock()
+	headingStyles = styles
+}
+
+// HeadingStyle returns the style for a heading of the given level (1-6)
+func HeadingStyle(level int) Style {
+	if level < 1 {
+		level = 1
+	} else if level > 6 {
+		level = 6
+	}
+	headingMu.RLock()
+	defer headingMu.RUnlock()
+	return headingStyles[level-1]
+}
+
 // ParseAST parses the input string into an AST
 func ParseAST(input string) *Node {
 	root := NewNode(NodeRoot)
-	lines := strings.Split(input, "\n")
+	lines := strings.Split(normalizeNewlines(input), "\n")
 
 	var currentList *Node
+	var quoteDepth int // Depth of the quote being continued, 0 outside quotes
 	var inCodeBlock bool
 	var codeBlockLang string
+	var codeBlockAttrs map[string]string
+	var codeBlockLine int
+	var codeBlockFence string // The opening ``` or ~~~ run
 	var codeBlockContent strings.Builder
+	refs := make(map[string]string) // Reference definitions: [id]: url
+	abbrs := make(map[string]string) // Abbreviations: *[ABBR]: expansion
+
+	endCodeBlock := func() {
+		node := NewNode(NodeCodeBlock)
+		node.Content = codeBlockContent.String()
+		node.Lang = codeBlockLang
+		node.Attrs = codeBlockAttrs
+		node.Line = codeBlockLine
+		root.AddChild(node)
+		codeBlockContent.Reset()
+		inCodeBlock = false
+		codeBlockLang, codeBlockAttrs, codeBlockFence = "", nil, ""
+	}
 
 	for i := 0; i < len(lines); i++ {
 		line := lines[i]
 		trimmed := strings.TrimSpace(line)
 
 		// 1. Handle Code Blocks (Stateful)
-		if matches := codeFenceRe.FindStringSubmatch(trimmed); matches != nil {
-			if inCodeBlock {
-				// End of code block
-				node := NewNode(NodeCodeBlock)
-				node.Content = codeBlockContent.String()
-				node.Lang = codeBlockLang
-				root.AddChild(node)
-				codeBlockContent.Reset()
-				inCodeBlock = false
-				codeBlockLang = ""
-			} else {
-				// Start of code block
-				inCodeBlock = true
-				codeBlockLang = strings.TrimSpace(matches[1])
+		if inCodeBlock {
+			if !closesFence(trimmed, codeBlockFence) {
+				// A final newline ends the last line rather than adding an empty one
+				if i < len(lines)-1 || line != "" {
+					codeBlockContent.WriteString(line + "\n")
+				}
+				continue
 			}
+			endCodeBlock()
 			continue
 		}
-		if inCodeBlock {
-			codeBlockContent.WriteString(line + "\n")
+		// An info string can't contain the fence character, which keeps a
+		// ~~~strike~~~ line a paragraph
+		if matches := codeFenceRe.FindStringSubmatch(trimmed); matches != nil && !strings.ContainsRune(matches[2], rune(matches[1][0])) {
+			// Start of code block
+			inCodeBlock = true
+			quoteDepth = 0
+			codeBlockLine = i + 1
+			codeBlockFence = matches[1]
+			codeBlockLang, codeBlockAttrs = parseInfoString(matches[2])
+			continue
+		}
+
+		// Reference and abbreviation definitions produce no output of their own
+		if matches := refDefRe.FindStringSubmatch(line); matches != nil {
+			refs[strings.ToLower(matches[1])] = matches[2]
+			continue
+		}
+		if matches := abbrDefRe.FindStringSubmatch(line); matches != nil {
+			abbrs[matches[1]] = matches[2]
 			continue
 		}
 
@@ -62,12 +156,21 @@ func ParseAST(input string) *Node {
 
 			if currentList == nil {
 				currentList = NewNode(NodeList)
+				currentList.Line = i + 1
 				root.AddChild(currentList)
 			}
 
+			quoteDepth = 0
 			item := NewNode(NodeListItem)
+			content := matches[3]
+			if task := taskItemRe.FindStringSubmatch(content); task != nil {
+				item.Task = true
+				item.Checked = task[1] != " "
+				content = task[2]
+			}
 			// Parse inline content of the list item
-			item.Children = parseInline(matches[3])
+			item.Children = parseInline(content)
+			item.Line = i + 1
 			currentList.AddChild(item)
 			continue
 		} else {
@@ -77,36 +180,46 @@ func ParseAST(input string) *Node {
 			}
 		}
 
+		// Only a paragraph line can lazily continue a quote
+		if trimmed == "" || headerBlockRe.MatchString(line) || hrBlockRe.MatchString(trimmed) {
+			quoteDepth = 0
+		}
+
 		// 3. Handle Headers
 		if matches := headerBlockRe.FindStringSubmatch(line); matches != nil {
 			level := len(matches[1])
 			content := matches[2]
 
-			style := Style{Bold: true}
-			if level == 1 {
-				style.Reverse = true
-			} else if level == 2 {
-				style.Underline = true
-			}
-
 			node := NewNode(NodeHeader) // Use specific type
-			node.Style = style
+			node.Style = HeadingStyle(level)
 			node.Children = parseInline(content)
+			node.Line = i + 1
 			root.AddChild(node)
 			continue
 		}
 
 		// 4. Handle Horizontal Rules
 		if hrBlockRe.MatchString(trimmed) {
-			root.AddChild(NewNode(NodeHR))
+			node := NewNode(NodeHR)
+			switch trimmed[0] {
+			case '*':
+				node.Rule = RuleHeavy
+			case '_':
+				node.Rule = RuleDouble
+			}
+			node.Line = i + 1
+			root.AddChild(node)
 			continue
 		}
 
 		// 5. Handle Blockquotes
 		if matches := quoteBlockRe.FindStringSubmatch(line); matches != nil {
 			node := NewNode(NodeQuote)
-			node.Children = parseInline(matches[1])
+			node.Depth = strings.Count(matches[1], ">")
+			node.Children = parseInline(matches[2])
+			node.Line = i + 1
 			root.AddChild(node)
+			quoteDepth = node.Depth
 			continue
 		}
 
@@ -116,43 +229,304 @@ func ParseAST(input string) *Node {
 			// Add a spacer? Or just ignore.
 			// Markdown usually treats empty lines as block separators.
 			// We can add an empty text block to force spacing.
-			root.AddChild(NewNode(NodeText)) // Empty text node acts as newline
+			spacer := NewNode(NodeText) // Empty text node acts as newline
+			spacer.Line = i + 1
+			root.AddChild(spacer)
+			continue
+		}
+
+		// Lazy continuation: a plain line right after a quote stays in it
+		if quoteDepth > 0 {
+			node := NewNode(NodeQuote)
+			node.Depth = quoteDepth
+			node.Children = parseInline(trimmed)
+			node.Line = i + 1
+			root.AddChild(node)
 			continue
 		}
 
 		node := NewNode(NodeBlock)
 		node.Children = parseInline(line)
+		node.Line = i + 1
 		root.AddChild(node)
 	}
 
+	// A code block still open runs to the end of the input, as in CommonMark
+	if inCodeBlock {
+		endCodeBlock()
+	}
+
+	// Definitions may come after their use, so resolve references last
+	resolveRefs(root, refs)
+	if len(abbrs) > 0 {
+		markAbbrs(root, abbrs)
+	}
+
+	// Inline nodes come from their block's line
+	for _, block := range root.Children {
+		inheritLine(block.Children, block.Line)
+	}
+
 	return root
 }
 
+// inheritLine gives nodes without a line, and their children, the given one
+func inheritLine(nodes []*Node, line int) {
+	for _, n := range nodes {
+		if n.Line == 0 {
+			n.Line = line
+		}
+		inheritLine(n.Children, n.Line)
+	}
+}
+
+// resolveRefs fills in the URL of reference-style links and images. References
+// without a matching definition are turned back into their literal text.
+func resolveRefs(n *Node, refs map[string]string) {
+	for _, child := range n.Children {
+		if (child.Type == NodeImage || child.Type == NodeLink) && child.Ref != "" {
+			if url, ok := refs[strings.ToLower(child.Ref)]; ok {
+				child.URL = url
+			} else {
+				*child = Node{Type: NodeText, Content: child.source}
+			}
+			child.Ref, child.source = "", ""
+		}
+		resolveRefs(child, refs)
+	}
+
+	// Re-join text split around a reference that turned back into text
+	merged := n.Children[:0]
+	for _, child := range n.Children {
+		if child.Type == NodeText && child.Content != "" {
+			merged = appendText(merged, child.Content)
+			continue
+		}
+		merged = append(merged, child)
+	}
+	n.Children = merged
+}
+
+// closesFence reports whether line ends a code block opened with fence: a
+// run of the same character, at least as long, and nothing else
+func closesFence(line, fence string) bool {
+	return len(line) >= len(fence) && strings.Trim(line, fence[:1]) == ""
+}
+
+// parseInfoString splits a code fence's info string into the language and
+// its attributes: "go {highlight=2 title="main.go"}" gives "go" and
+// {highlight: 2, title: main.go}. Braces are optional and values may be quoted.
+func parseInfoString(info string) (string, map[string]string) {
+	info = strings.TrimSpace(info)
+	lang := info
+	rest := ""
+	if i := strings.IndexAny(info, " \t{"); i >= 0 {
+		lang, rest = info[:i], info[i:]
+	}
+
+	rest = strings.TrimSpace(rest)
+	rest = strings.TrimPrefix(rest, "{")
+	rest = strings.TrimSuffix(rest, "}")
+	var attrs map[string]string
+	for _, field := range infoFields(rest) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || key == "" {
+			continue
+		}
+		if attrs == nil {
+			attrs = make(map[string]string)
+		}
+		attrs[key] = strings.Trim(value, `"'`)
+	}
+	return lang, attrs
+}
+
+// infoFields splits s at spaces and tabs outside quotes, so a quoted value
+// keeps its spaces: title="main file.go" is one field.
+func infoFields(s string) []string {
+	var fields []string
+	start := -1
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			continue
+		case c == ' ' || c == '\t':
+			if start >= 0 {
+				fields = append(fields, s[start:i])
+				start = -1
+			}
+			continue
+		case c == '"' || c == '\'':
+			quote = c
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		fields = append(fields, s[start:])
+	}
+	return fields
+}
+
+// markAbbrs wraps each whole-word occurrence of a defined abbreviation in
+// text under n in a NodeAbbr. Code is left alone.
+func markAbbrs(n *Node, abbrs map[string]string) {
+	var out []*Node
+	for _, child := range n.Children {
+		switch {
+		case child.Type == NodeText && child.Content != "":
+			out = append(out, splitAbbrs(child.Content, abbrs)...)
+			continue
+		case child.Type != NodeCodeBlock && !child.Code:
+			markAbbrs(child, abbrs)
+		}
+		out = append(out, child)
+	}
+	n.Children = out
+}
+
+// splitAbbrs splits text into text and NodeAbbr nodes
+func splitAbbrs(text string, abbrs map[string]string) []*Node {
+	var nodes []*Node
+	for text != "" {
+		// Earliest occurrence, the longest abbreviation on a tie
+		start, abbr := -1, ""
+		for a := range abbrs {
+			i := indexWord(text, a)
+			if i >= 0 && (start < 0 || i < start || i == start && len(a) > len(abbr)) {
+				start, abbr = i, a
+			}
+		}
+		if start < 0 {
+			break
+		}
+		if start > 0 {
+			nodes = append(nodes, &Node{Type: NodeText, Content: text[:start]})
+		}
+		node := NewNode(NodeAbbr)
+		node.Content = abbrs[abbr]
+		node.Style = AbbrStyle
+		node.AddChild(&Node{Type: NodeText, Content: abbr})
+		nodes = append(nodes, node)
+		text = text[start+len(abbr):]
+	}
+	if text != "" {
+		nodes = append(nodes, &Node{Type: NodeText, Content: text})
+	}
+	return nodes
+}
+
+// indexWord returns the index of the first occurrence of word in s that isn't
+// part of a longer word, or -1
+func indexWord(s, word string) int {
+	for from := 0; ; {
+		i := strings.Index(s[from:], word)
+		if i < 0 {
+			return -1
+		}
+		i += from
+		before, _ := utf8.DecodeLastRuneInString(s[:i])
+		after, _ := utf8.DecodeRuneInString(s[i+len(word):])
+		if !isWordRune(before) && !isWordRune(after) {
+			return i
+		}
+		from = i + 1
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// delimitedStyles maps the doubled delimiters of the inline styles that share
+// their syntax with Parse to the style they apply
+var delimitedStyles = map[string]Style{
+	"~~": {Strike: true},
+	"--": {Dim: true},
+	"::": {Blink: true},
+	"!!": {Reverse: true},
+	"??": {Hidden: true},
+}
+
+// Unicode super- and subscript forms, by the character they raise or lower
+var (
+	superscripts = scriptTable(
+		"0123456789+-=()abcdefghijklmnoprstuvwxyzABDEGHIJKLMNOPRTUVW",
+		"⁰¹²³⁴⁵⁶⁷⁸⁹⁺⁻⁼⁽⁾ᵃᵇᶜᵈᵉᶠᵍʰⁱʲᵏˡᵐⁿᵒᵖʳˢᵗᵘᵛʷˣʸᶻᴬᴮᴰᴱᴳᴴᴵᴶᴷᴸᴹᴺᴼᴾᴿᵀᵁⱽᵂ")
+	subscripts = scriptTable(
+		"0123456789+-=()aehijklmnoprstuvx",
+		"₀₁₂₃₄₅₆₇₈₉₊₋₌₍₎ₐₑₕᵢⱼₖₗₘₙₒₚᵣₛₜᵤᵥₓ")
+)
+
+func scriptTable(from, to string) map[rune]rune {
+	table := map[rune]rune{}
+	dst := []rune(to)
+	for i, r := range []rune(from) {
+		table[r] = dst[i]
+	}
+	return table
+}
+
+// toScript rewrites s with the forms in table, reporting false if some
+// character has none
+func toScript(s string, table map[rune]rune) (string, bool) {
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		sr, ok := table[r]
+		if !ok {
+			return "", false
+		}
+		out = append(out, sr)
+	}
+	return string(out), true
+}
+
 // parseInline parses inline styles, colors, and holes
 func parseInline(text string) []*Node {
 	var nodes []*Node
 
 	lastIndex := 0
-	matches := inlineTokenRe.FindAllStringIndex(text, -1)
+	tokenizer := inlineTokenizer{text: text}
+	matches := tokenizer.tokens()
 
 	for _, match := range matches {
 		start, end := match[0], match[1]
 
 		// Add preceding text
 		if start > lastIndex {
-			nodes = append(nodes, &Node{
-				Type:    NodeText,
-				Content: text[lastIndex:start],
-			})
+			nodes = appendPlain(nodes, text[lastIndex:start])
 		}
 
 		token := text[start:end]
 
-		if token == "%v" {
+		if token[0] == '`' {
+			// Inline code: content is literal, no nested markup
+			styleNode := NewNode(NodeStyle)
+			styleNode.Style = CodeStyle
+			styleNode.Code = true
+			styleNode.AddChild(&Node{Type: NodeText, Content: token[1 : len(token)-1]})
+			nodes = append(nodes, styleNode)
+		} else if token[0] == '\\' {
+			// Escaped character: emit it literally
+			nodes = appendText(nodes, token[1:])
+		} else if token == "%v" {
 			nodes = append(nodes, &Node{
 				Type:   NodeHole,
 				HoleID: -1,
 			})
+		} else if strings.HasPrefix(token, "%{") {
+			// Named hole: %{name}
+			nodes = append(nodes, &Node{
+				Type:     NodeHole,
+				HoleID:   -1,
+				HoleName: token[2 : len(token)-1],
+			})
 		} else if strings.HasPrefix(token, "**") {
 			// Bold
 			content := token[2 : len(token)-2]
@@ -167,6 +541,33 @@ func parseInline(text string) []*Node {
 			styleNode.Style = Style{Underline: true}
 			styleNode.Children = parseInline(content)
 			nodes = append(nodes, styleNode)
+		} else if strings.HasPrefix(token, "![") {
+			nodes = append(nodes, parseLink(token))
+		} else if strings.HasPrefix(token, "[") {
+			nodes = append(nodes, parseLink(token))
+		} else if len(token) > 2 && (token[0] == '^' || token[0] == '~' && token[1] != '~') {
+			// Superscript or subscript: Unicode forms if every character has
+			// one, otherwise the content styled with ScriptStyle
+			table := superscripts
+			if token[0] == '~' {
+				table = subscripts
+			}
+			content := token[1 : len(token)-1]
+			if script, ok := toScript(content, table); ok {
+				nodes = appendText(nodes, script)
+			} else {
+				styleNode := NewNode(NodeStyle)
+				styleNode.Style = ScriptStyle
+				styleNode.Children = parseInline(content)
+				nodes = append(nodes, styleNode)
+			}
+		} else if style, ok := delimitedStyles[token[:2]]; ok {
+			// Strikethrough, dim, blink, reverse or hidden
+			content := token[2 : len(token)-2]
+			styleNode := NewNode(NodeStyle)
+			styleNode.Style = style
+			styleNode.Children = parseInline(content)
+			nodes = append(nodes, styleNode)
 		} else if strings.Contains(token, "#") {
 			// Color: #red(text) or !#red(text)
 			isBg := strings.HasPrefix(token, "!")
@@ -184,12 +585,10 @@ func parseInline(text string) []*Node {
 				content := token[startParen+1 : endParen]
 
 				styleNode := NewNode(NodeStyle)
-				ansiColor := GetColorCode(colorName)
-
 				if isBg {
-					styleNode.Style = Style{BgColor: ansiColor}
+					styleNode.Style = Style{BgColor: GetBgColorCode(colorName)}
 				} else {
-					styleNode.Style = Style{Color: ansiColor}
+					styleNode.Style = Style{Color: GetColorCode(colorName)}
 				}
 
 				styleNode.Children = parseInline(content)
@@ -205,11 +604,100 @@ func parseInline(text string) []*Node {
 
 	// Add remaining text
 	if lastIndex < len(text) {
-		nodes = append(nodes, &Node{
-			Type:    NodeText,
-			Content: text[lastIndex:],
-		})
+		nodes = appendPlain(nodes, text[lastIndex:])
 	}
 
 	return nodes
 }
+
+// parseLink builds a link or image node from [text](url "title"), [text][id] or
+// [text][], each optionally prefixed with ! for an image.
+func parseLink(token string) *Node {
+	node := NewNode(NodeLink)
+	node.source = token
+	if token[0] == '!' {
+		node.Type = NodeImage
+		token = token[1:]
+	}
+	closeAlt := strings.Index(token, "]")
+	node.Content = token[1:closeAlt]
+	if node.Type == NodeLink {
+		node.Style = Style{Underline: true, Color: GetColorCode("blue")}
+		node.Children = parseInline(node.Content)
+	}
+
+	rest := token[closeAlt+1:]
+	if rest[0] == '(' {
+		fields := strings.Fields(rest[1 : len(rest)-1])
+		if len(fields) > 0 {
+			node.URL = fields[0]
+		}
+	} else {
+		node.Ref = rest[1 : len(rest)-1]
+		if node.Ref == "" {
+			node.Ref = node.Content // Collapsed reference: ![alt][]
+		}
+	}
+	return node
+}
+
+// appendPlain adds unmarked text, turning bare URLs in it into links.
+func appendPlain(nodes []*Node, text string) []*Node {
+	last := 0
+	for _, loc := range autolinkRe.FindAllStringSubmatchIndex(text, -1) {
+		start, end := loc[2], loc[3]
+		end = start + len(trimURL(text[start:end]))
+
+		if start > last {
+			nodes = appendText(nodes, text[last:start])
+		}
+
+		url := text[start:end]
+		link := NewNode(NodeLink)
+		link.Content = url
+		link.URL = url
+		if strings.HasPrefix(url, "www.") {
+			link.URL = "http://" + url
+		}
+		link.Style = Style{Underline: true, Color: GetColorCode("blue")}
+		link.AddChild(&Node{Type: NodeText, Content: url})
+		nodes = append(nodes, link)
+
+		last = end
+	}
+	if last < len(text) {
+		nodes = appendText(nodes, text[last:])
+	}
+	return nodes
+}
+
+// trimURL drops trailing punctuation that more likely ends the sentence than
+// the URL, including a ")" with no matching "(" in the URL.
+func trimURL(url string) string {
+	for len(url) > 0 {
+		last := url[len(url)-1]
+		if strings.IndexByte(".,:;!?'\"*_~", last) >= 0 {
+			url = url[:len(url)-1]
+			continue
+		}
+		if last == ')' && strings.Count(url, ")") > strings.Count(url, "(") {
+			url = url[:len(url)-1]
+			continue
+		}
+		break
+	}
+	return url
+}
+
+// appendText adds a text node, merging it into the previous node if that is
+// also plain text (so escapes don't fragment a run of text).
+func appendText(nodes []*Node, text string) []*Node {
+	if n := len(nodes); n > 0 && nodes[n-1].Type == NodeText {
+		nodes[n-1].Content += text
+		return nodes
+	}
+	return append(nodes, &Node{
+		Type:    NodeText,
+		Content: text,
+	})
+}
@@ -1,7 +1,11 @@
 package basement
 
 import (
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestParseAST(t *testing.T) {
@@ -55,3 +59,684 @@ func TestParseAST(t *testing.T) {
 		t.Errorf("Node 4 mismatch: %+v", children[3])
 	}
 }
+
+func TestHeadingStylesByLevel(t *testing.T) {
+	root := ParseAST("# a\n## b\n### c\n###### f")
+	if len(root.Children) != 4 {
+		t.Fatalf("Expected 4 headers, got %d", len(root.Children))
+	}
+	h1, h2, h3, h6 := root.Children[0].Style, root.Children[1].Style, root.Children[2].Style, root.Children[3].Style
+	if h3 == h1 || h3 == h2 || h3 == h6 {
+		t.Errorf("Expected h3 to have its own style, got h1=%+v h2=%+v h3=%+v h6=%+v", h1, h2, h3, h6)
+	}
+
+	defer SetHeadingStyles([6]Style{HeadingStyle(1), HeadingStyle(2), HeadingStyle(3), HeadingStyle(4), HeadingStyle(5), HeadingStyle(6)})
+	SetHeadingStyles([6]Style{{Italic: true}})
+	if got := ParseAST("# a").Children[0].Style; got != (Style{Italic: true}) {
+		t.Errorf("Expected the custom h1 style, got %+v", got)
+	}
+}
+
+func TestParseTaskList(t *testing.T) {
+	root := ParseAST("- [ ] write **tests**\n* [x] ship\n- [X]\n- plain\n- [y] not a task\n- [x](link)")
+	items := root.Children[0].Children
+	if len(items) != 6 {
+		t.Fatalf("Expected 6 items, got %d", len(items))
+	}
+	tests := []struct {
+		task, checked bool
+		text          string
+	}{
+		{true, false, "write tests"},
+		{true, true, "ship"},
+		{true, true, ""},
+		{false, false, "plain"},
+		{false, false, "[y] not a task"},
+	}
+	for i, tt := range tests {
+		item := items[i]
+		if item.Task != tt.task || item.Checked != tt.checked {
+			t.Errorf("Item %d: expected task %v checked %v, got %v %v", i, tt.task, tt.checked, item.Task, item.Checked)
+		}
+		if got := nodeText(item); got != tt.text {
+			t.Errorf("Item %d: expected text %q, got %q", i, tt.text, got)
+		}
+	}
+	if items[5].Task || items[5].Children[0].Type != NodeLink {
+		t.Errorf("Expected [x](link) to stay a link, got %+v", items[5].Children[0])
+	}
+	if items[0].Children[1].Style != (Style{Bold: true}) {
+		t.Errorf("Expected the task text parsed as markup, got %+v", items[0].Children)
+	}
+}
+
+func TestDumpAST(t *testing.T) {
+	root := ParseAST("# Hi **x** %v")
+	root.Children[0].Children[3].HoleID = 0
+	got := DumpAST(root)
+	want := []string{
+		"Root",
+		"  Header line=1 style=",
+		"    Text \"Hi \" line=1",
+		"    Style line=1 style=bold",
+		"      Text \"x\" line=1",
+		"    Text \" \" line=1",
+		"    Hole id=0 line=1",
+	}
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %d lines, got:\n%s", len(want), got)
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("Line %d: expected %q, got %q", i, prefix, lines[i])
+		}
+	}
+}
+
+// nodeText concatenates the text under n
+func nodeText(n *Node) string {
+	text := n.Content
+	for _, child := range n.Children {
+		text += nodeText(child)
+	}
+	return text
+}
+
+func TestParseASTLineNumbers(t *testing.T) {
+	root := ParseAST("intro\n\n## Title\n- one\n- two **bold**\n\n```go\ncode\n```\nend")
+	var header, list, code, end *Node
+	for _, n := range root.Children {
+		switch n.Type {
+		case NodeHeader:
+			header = n
+		case NodeList:
+			list = n
+		case NodeCodeBlock:
+			code = n
+		case NodeBlock:
+			end = n
+		}
+	}
+	if header == nil || list == nil || code == nil || end == nil {
+		t.Fatalf("Expected a header, list, code block and paragraph, got %+v", root.Children)
+	}
+
+	if header.Line != 3 {
+		t.Errorf("Expected the header on line 3, got %d", header.Line)
+	}
+	if list.Line != 4 || list.Children[0].Line != 4 || list.Children[1].Line != 5 {
+		t.Errorf("Expected the list items on lines 4 and 5, got %d and %d", list.Children[0].Line, list.Children[1].Line)
+	}
+	if bold := list.Children[1].Children[1]; bold.Line != 5 || bold.Children[0].Line != 5 {
+		t.Errorf("Expected inline nodes to carry their item's line, got %d", bold.Line)
+	}
+	if code.Line != 7 {
+		t.Errorf("Expected the code block at its opening fence, line 7, got %d", code.Line)
+	}
+	if end.Line != 10 || root.Line != 0 {
+		t.Errorf("Expected the last paragraph on line 10 and no line on the root, got %d and %d", end.Line, root.Line)
+	}
+}
+
+func TestHeaderTheme(t *testing.T) {
+	defer SetHeaderTheme(nil)
+	cyanBold := Style{Bold: true, Color: GetColorCode("cyan")}
+	SetHeaderTheme(HeaderTheme{1: cyanBold, 3: {Dim: true}, 7: {Blink: true}})
+
+	root := ParseAST("# a\n## b\n### c\n#### d\n##### e\n###### f")
+	want := []Style{cyanBold, defaultHeadingStyles[1], {Dim: true}, defaultHeadingStyles[3], defaultHeadingStyles[4], defaultHeadingStyles[5]}
+	if len(root.Children) != len(want) {
+		t.Fatalf("Expected %d headers, got %d", len(want), len(root.Children))
+	}
+	for i, h := range root.Children {
+		if h.Type != NodeHeader || h.Style != want[i] {
+			t.Errorf("h%d: expected %+v, got %+v", i+1, want[i], h.Style)
+		}
+	}
+
+	SetHeaderTheme(nil)
+	if got := ParseAST("# a").Children[0].Style; got != defaultHeadingStyles[0] {
+		t.Errorf("Expected a nil theme to restore the defaults, got %+v", got)
+	}
+}
+
+func TestParseInlineEscapes(t *testing.T) {
+	nodes := parseInline(`\*not bold\*`)
+	if len(nodes) != 1 || nodes[0].Type != NodeText || nodes[0].Content != "*not bold*" {
+		t.Fatalf("Expected a single text node \"*not bold*\", got %+v", nodes)
+	}
+
+	nodes = parseInline(`\*\*still not bold\*\* and \%v and \#red(x) and \~~`)
+	if len(nodes) != 1 || nodes[0].Type != NodeText {
+		t.Fatalf("Expected a single text node, got %+v", nodes)
+	}
+	if want := "**still not bold** and %v and #red(x) and ~~"; nodes[0].Content != want {
+		t.Errorf("Expected %q, got %q", want, nodes[0].Content)
+	}
+
+	// Escapes inside a styled span still work
+	nodes = parseInline(`**a\*b**`)
+	if len(nodes) != 1 || nodes[0].Type != NodeStyle || !nodes[0].Style.Bold {
+		t.Fatalf("Expected a single bold node, got %+v", nodes)
+	}
+	if got := nodes[0].Children[0].Content; got != "a*b" {
+		t.Errorf("Expected bold content \"a*b\", got %q", got)
+	}
+}
+
+func TestParseInlineScripts(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"19^th^ of May", "19ᵗʰ of May"},
+		{"H~2~O", "H₂O"},
+		{"x^(n+1)^", "x⁽ⁿ⁺¹⁾"},
+		{"a ^ b ^ c", "a ^ b ^ c"}, // Spaces inside: not a script
+		{"~/x and ~", "~/x and ~"},
+	}
+	for _, tt := range tests {
+		nodes := parseInline(tt.in)
+		if len(nodes) != 1 || nodes[0].Type != NodeText || nodes[0].Content != tt.want {
+			t.Errorf("%q: expected text %q, got %+v", tt.in, tt.want, nodes)
+		}
+	}
+
+	// Characters without a Unicode form fall back to ScriptStyle
+	nodes := parseInline("x~q~")
+	if len(nodes) != 2 || nodes[1].Type != NodeStyle || nodes[1].Style != ScriptStyle ||
+		nodes[1].Children[0].Content != "q" {
+		t.Errorf("Expected a styled fallback for ~q~, got %+v", nodes)
+	}
+
+	// Double tildes stay strikethrough, even around a subscript
+	nodes = parseInline("~~x~~")
+	if len(nodes) != 1 || nodes[0].Style != (Style{Strike: true}) || nodes[0].Children[0].Content != "x" {
+		t.Errorf("Expected ~~x~~ to stay strikethrough, got %+v", nodes)
+	}
+	nodes = parseInline("~~H~2~O~~")
+	if len(nodes) != 1 || !nodes[0].Style.Strike || nodes[0].Children[0].Content != "H₂O" {
+		t.Errorf("Expected a subscript inside strikethrough, got %+v", nodes)
+	}
+}
+
+func TestParseInlineDelimitedStyles(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Style
+	}{
+		{"a --dim-- b", Style{Dim: true}},
+		{"a ::blink:: b", Style{Blink: true}},
+		{"a !!reverse!! b", Style{Reverse: true}},
+		{"a ??hidden?? b", Style{Hidden: true}},
+		{"a ~~strike~~ b", Style{Strike: true}},
+	}
+	for _, tt := range tests {
+		nodes := parseInline(tt.in)
+		if len(nodes) != 3 || nodes[1].Type != NodeStyle || nodes[1].Style != tt.want {
+			t.Errorf("%q: expected a %+v node between two texts, got %+v", tt.in, tt.want, nodes)
+			continue
+		}
+		if nodes[0].Content != "a " || nodes[2].Content != " b" {
+			t.Errorf("%q: expected surrounding text kept, got %+v", tt.in, nodes)
+		}
+	}
+
+	// Nested markup inside is parsed too
+	nodes := parseInline("!!**x**!!")
+	if len(nodes) != 1 || !nodes[0].Style.Reverse || !nodes[0].Children[0].Style.Bold {
+		t.Errorf("Expected bold nested in reverse, got %+v", nodes)
+	}
+
+	// Delimiters must hug their content, so ordinary prose stays as text
+	for _, in := range []string{
+		"Run with --color=always or --verbose",
+		"std::vector and std::map",
+		"Really?? Yes??",
+		"a -- b -- c",
+		"!!!!!! ???? ,,  -- ---",
+	} {
+		var got strings.Builder
+		for _, n := range parseInline(in) {
+			if n.Type != NodeText {
+				t.Errorf("%q: expected only text, got %+v", in, n)
+			}
+			got.WriteString(n.Content)
+		}
+		if got.String() != in {
+			t.Errorf("%q: expected the text unchanged, got %q", in, got.String())
+		}
+	}
+}
+
+func TestParseRuleStyles(t *testing.T) {
+	root := ParseAST("---\n***\n___")
+	want := []RuleStyle{RuleLight, RuleHeavy, RuleDouble}
+	if len(root.Children) != len(want) {
+		t.Fatalf("Expected %d rules, got %+v", len(want), root.Children)
+	}
+	for i, n := range root.Children {
+		if n.Type != NodeHR || n.Rule != want[i] {
+			t.Errorf("Rule %d: expected style %d, got %+v", i, want[i], n)
+		}
+	}
+}
+
+func TestParseASTCRLF(t *testing.T) {
+	lf := "# Title\n\n---\n> quote\n- item\n```go\ncode\n```\ntext"
+	crlf := strings.ReplaceAll(lf, "\n", "\r\n")
+	for _, in := range []string{crlf, strings.ReplaceAll(lf, "\n", "\r")} {
+		got, want := ParseAST(in), ParseAST(lf)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: expected the same AST as with \\n line endings", in)
+		}
+	}
+	if out := Parse(crlf); strings.Contains(out, "\r") || out != Parse(lf) {
+		t.Errorf("Expected Parse to normalize line endings, got %q", out)
+	}
+}
+
+func TestParseTildeFences(t *testing.T) {
+	root := ParseAST("~~~python\nprint(1)\n~~~\nafter")
+	if len(root.Children) != 2 || root.Children[0].Type != NodeCodeBlock {
+		t.Fatalf("Expected a code block then a paragraph, got %+v", root.Children)
+	}
+	if code := root.Children[0]; code.Lang != "python" || code.Content != "print(1)\n" {
+		t.Errorf("Expected python code \"print(1)\", got %q %q", code.Lang, code.Content)
+	}
+
+	// Backticks inside a tilde block are code, and only a tilde run closes it
+	root = ParseAST("~~~~\n```go\nx\n```\n~~~\n~~~~")
+	if len(root.Children) != 1 || root.Children[0].Content != "```go\nx\n```\n~~~\n" {
+		t.Errorf("Expected one block holding the backtick fences, got %+v", root.Children)
+	}
+
+	// A backtick fence isn't closed by tildes: the block runs to the end
+	root = ParseAST("```\ncode\n~~~\nmore")
+	if len(root.Children) != 1 || root.Children[0].Type != NodeCodeBlock || root.Children[0].Content != "code\n~~~\nmore\n" {
+		t.Errorf("Expected the unclosed block kept to the end, got %+v", root.Children)
+	}
+	root = ParseAST("intro\n```go\nx\n\n")
+	if len(root.Children) != 2 || root.Children[1].Lang != "go" || root.Children[1].Content != "x\n\n" || root.Children[1].Line != 2 {
+		t.Errorf("Expected the unclosed block up to the final newline, got %+v", root.Children)
+	}
+
+	// Strikethrough with three tildes is not a fence
+	root = ParseAST("~~~gone~~~")
+	if len(root.Children) != 1 || root.Children[0].Type != NodeBlock {
+		t.Errorf("Expected a paragraph, got %+v", root.Children)
+	}
+}
+
+func TestParseFenceInfoString(t *testing.T) {
+	tests := []struct {
+		info  string
+		lang  string
+		attrs map[string]string
+	}{
+		{"go", "go", nil},
+		{"go {highlight=2}", "go", map[string]string{"highlight": "2"}},
+		{"go{highlight=\"1,3-4\" title=main.go}", "go", map[string]string{"highlight": "1,3-4", "title": "main.go"}},
+		{"python highlight=5", "python", map[string]string{"highlight": "5"}},
+		{"go {title=\"main file.go\" highlight=2}", "go", map[string]string{"title": "main file.go", "highlight": "2"}},
+		{"sh title='run it.sh'", "sh", map[string]string{"title": "run it.sh"}},
+		{"{highlight=1}", "", map[string]string{"highlight": "1"}},
+		{"", "", nil},
+	}
+	for _, tt := range tests {
+		root := ParseAST("```" + tt.info + "\nx\n```")
+		n := root.Children[0]
+		if n.Type != NodeCodeBlock || n.Lang != tt.lang || !reflect.DeepEqual(n.Attrs, tt.attrs) {
+			t.Errorf("%q: expected lang %q attrs %v, got %q %v", tt.info, tt.lang, tt.attrs, n.Lang, n.Attrs)
+		}
+	}
+}
+
+func TestParseInlineCode(t *testing.T) {
+	nodes := parseInline("run `x` now")
+	if len(nodes) != 3 {
+		t.Fatalf("Expected 3 nodes, got %d: %+v", len(nodes), nodes)
+	}
+	code := nodes[1]
+	if code.Type != NodeStyle || code.Style != CodeStyle {
+		t.Fatalf("Expected a code-styled node, got %+v", code)
+	}
+	if len(code.Children) != 1 || code.Children[0].Content != "x" {
+		t.Errorf("Expected code content \"x\" without backticks, got %+v", code.Children)
+	}
+
+	// Markup inside a code span stays literal
+	nodes = parseInline("`**a**`")
+	if len(nodes) != 1 || nodes[0].Children[0].Content != "**a**" {
+		t.Errorf("Expected literal \"**a**\", got %+v", nodes)
+	}
+
+	// Fences are still code blocks
+	root := ParseAST("```go\nx := 1\n```")
+	if len(root.Children) != 1 || root.Children[0].Type != NodeCodeBlock {
+		t.Errorf("Expected a single code block, got %+v", root.Children)
+	}
+}
+
+func TestParseNestedQuote(t *testing.T) {
+	root := ParseAST(">> text\n> > > deeper\nlazy line\n\nafter")
+
+	if len(root.Children) != 5 {
+		t.Fatalf("Expected 5 blocks, got %d", len(root.Children))
+	}
+
+	quote := root.Children[0]
+	if quote.Type != NodeQuote || quote.Depth != 2 {
+		t.Errorf("Expected quote of depth 2, got type %d depth %d", quote.Type, quote.Depth)
+	}
+	if quote.Children[0].Content != "text" {
+		t.Errorf("Expected quote content \"text\", got %q", quote.Children[0].Content)
+	}
+
+	if d := root.Children[1].Depth; d != 3 {
+		t.Errorf("Expected depth 3 for \"> > >\", got %d", d)
+	}
+
+	lazy := root.Children[2]
+	if lazy.Type != NodeQuote || lazy.Depth != 3 {
+		t.Errorf("Expected lazy continuation to inherit depth 3, got type %d depth %d", lazy.Type, lazy.Depth)
+	}
+
+	if after := root.Children[4]; after.Type != NodeBlock {
+		t.Errorf("Expected a paragraph after the blank line, got type %d", after.Type)
+	}
+}
+
+func TestParseASTEscapedHole(t *testing.T) {
+	root := ParseAST(`cost: \%v and \#red(x)`)
+	block := root.Children[0]
+	if len(block.Children) != 1 || block.Children[0].Type != NodeText {
+		t.Fatalf("Expected a single text node, got %+v", block.Children)
+	}
+	if want := "cost: %v and #red(x)"; block.Children[0].Content != want {
+		t.Errorf("Expected %q, got %q", want, block.Children[0].Content)
+	}
+}
+
+func TestParseImage(t *testing.T) {
+	root := ParseAST(`see ![logo](https://example.com/logo.png "Logo") here`)
+	children := root.Children[0].Children
+	if len(children) != 3 {
+		t.Fatalf("Expected 3 inline nodes, got %d", len(children))
+	}
+
+	img := children[1]
+	if img.Type != NodeImage || img.Content != "logo" || img.URL != "https://example.com/logo.png" {
+		t.Errorf("Image mismatch: %+v", img)
+	}
+}
+
+func TestParseImageReference(t *testing.T) {
+	root := ParseAST("![Alt][Cat] and ![dog][]\n\n[cat]: https://example.com/cat.png \"A cat\"\n[dog]: /dog.png\n![x][missing]")
+
+	// The definition lines produce no blocks
+	if len(root.Children) != 3 {
+		t.Fatalf("Expected 3 blocks, got %d", len(root.Children))
+	}
+
+	line := root.Children[0].Children
+	if img := line[0]; img.Type != NodeImage || img.URL != "https://example.com/cat.png" || img.Content != "Alt" {
+		t.Errorf("Reference image mismatch: %+v", img)
+	}
+	if img := line[2]; img.Type != NodeImage || img.URL != "/dog.png" {
+		t.Errorf("Collapsed reference image mismatch: %+v", img)
+	}
+
+	missing := root.Children[2].Children[0]
+	if missing.Type != NodeText || missing.Content != "![x][missing]" {
+		t.Errorf("Expected unresolved reference as text, got %+v", missing)
+	}
+}
+
+func TestParseUnresolvedCollapsedReference(t *testing.T) {
+	cases := map[string]string{
+		"see [x][] here": "see [x][] here",
+		"![alt][]":       "![alt][]",
+		"[a][A] and [b]": "[a][A] and [b]",
+	}
+	for input, want := range cases {
+		line := ParseAST(input).Children[0].Children
+		if len(line) != 1 || line[0].Type != NodeText || line[0].Content != want {
+			t.Errorf("ParseAST(%q) = %v, want the text %q", input, line, want)
+		}
+	}
+}
+
+func TestParseReferenceInNestedMarkup(t *testing.T) {
+	root := ParseAST("- see **[the docs][d]**\n> ![logo][d]\n\n[d]: https://d.example")
+
+	item := root.Children[0].Children[0]
+	bold := item.Children[1]
+	if link := bold.Children[0]; link.Type != NodeLink || link.URL != "https://d.example" {
+		t.Errorf("Expected the reference inside bold in a list item resolved, got %+v", link)
+	}
+	if img := root.Children[1].Children[0]; img.Type != NodeImage || img.URL != "https://d.example" {
+		t.Errorf("Expected the reference inside a quote resolved, got %+v", img)
+	}
+}
+
+func TestParseAbbreviations(t *testing.T) {
+	root := ParseAST("HTML, xxxHTMLyyy, HTML5 and `HTML`\n\n*[HTML]: Hyper Text Markup Language")
+
+	if len(root.Children) != 2 {
+		t.Fatalf("Expected the definition line to be dropped, got %d blocks", len(root.Children))
+	}
+	line := root.Children[0].Children
+	abbr := line[0]
+	if abbr.Type != NodeAbbr || abbr.Content != "Hyper Text Markup Language" || abbr.Style != AbbrStyle {
+		t.Fatalf("Expected a leading abbreviation, got %+v", abbr)
+	}
+	if abbr.Children[0].Content != "HTML" {
+		t.Errorf("Expected the abbreviation text kept, got %+v", abbr.Children)
+	}
+
+	// Only whole words match, and code spans are left alone
+	var abbrs int
+	for _, n := range line {
+		if n.Type == NodeAbbr {
+			abbrs++
+		}
+	}
+	if abbrs != 1 || line[1].Content != ", xxxHTMLyyy, HTML5 and " {
+		t.Errorf("Expected partial matches left as text, got %+v", line)
+	}
+
+	// Highlighted text looks like code but isn't
+	line = ParseAST("!!API!! and `API`\n*[API]: Application Programming Interface").Children[0].Children
+	if hl := line[0]; hl.Code || len(hl.Children) != 1 || hl.Children[0].Type != NodeAbbr {
+		t.Errorf("Expected the abbreviation marked in highlighted text, got %+v", hl.Children)
+	}
+	if code := line[2]; !code.Code || code.Children[0].Type != NodeText {
+		t.Errorf("Expected the code span left alone, got %+v", code.Children)
+	}
+}
+
+func TestParseLinks(t *testing.T) {
+	root := ParseAST("[inline](https://a.example) [ref][Docs] ![img][docs] [gone][nope]\n\n[docs]: https://docs.example \"Docs\"")
+
+	if len(root.Children) != 2 {
+		t.Fatalf("Expected the definition line to be dropped, got %d blocks", len(root.Children))
+	}
+
+	line := root.Children[0].Children
+	inline := line[0]
+	if inline.Type != NodeLink || inline.URL != "https://a.example" || !inline.Style.Underline {
+		t.Errorf("Inline link mismatch: %+v", inline)
+	}
+	if len(inline.Children) != 1 || inline.Children[0].Content != "inline" {
+		t.Errorf("Expected link text child \"inline\", got %+v", inline.Children)
+	}
+
+	if ref := line[2]; ref.Type != NodeLink || ref.URL != "https://docs.example" {
+		t.Errorf("Reference link mismatch: %+v", ref)
+	}
+	if img := line[4]; img.Type != NodeImage || img.URL != "https://docs.example" {
+		t.Errorf("Reference image mismatch: %+v", img)
+	}
+
+	// An undefined reference keeps its bracket text
+	if gone := line[5]; gone.Type != NodeText || gone.Content != " [gone][nope]" {
+		t.Errorf("Expected undefined reference as text, got %+v", gone)
+	}
+}
+
+func TestAutolink(t *testing.T) {
+	nodes := parseInline("see https://github.com/nodeca/pica for more")
+	if len(nodes) != 3 {
+		t.Fatalf("Expected 3 nodes, got %+v", nodes)
+	}
+	if link := nodes[1]; link.Type != NodeLink || link.URL != "https://github.com/nodeca/pica" {
+		t.Errorf("Mid-sentence link mismatch: %+v", link)
+	}
+	if nodes[2].Content != " for more" {
+		t.Errorf("Expected trailing text \" for more\", got %q", nodes[2].Content)
+	}
+
+	nodes = parseInline("Visit www.example.com/docs.")
+	if len(nodes) != 3 {
+		t.Fatalf("Expected 3 nodes, got %+v", nodes)
+	}
+	if link := nodes[1]; link.Type != NodeLink || link.URL != "http://www.example.com/docs" || link.Content != "www.example.com/docs" {
+		t.Errorf("Sentence-ending link mismatch: %+v", link)
+	}
+	if nodes[2].Content != "." {
+		t.Errorf("Expected the period to stay outside the link, got %q", nodes[2].Content)
+	}
+
+	nodes = parseInline("(https://en.wikipedia.org/wiki/Go_(language))")
+	if link := nodes[1]; link.URL != "https://en.wikipedia.org/wiki/Go_(language)" {
+		t.Errorf("Expected balanced parentheses to stay in the URL, got %q", link.URL)
+	}
+
+	// Not inside a word, and not without a host
+	for _, text := range []string{"xwww.example.com", "nothttps://example.com", "see https:// here", "bare https://.", "www."} {
+		if nodes := parseInline(text); len(nodes) != 1 || nodes[0].Type != NodeText {
+			t.Errorf("Expected %q to stay text, got %+v", text, nodes)
+		}
+	}
+}
+
+func TestAutolinkSkipsLinksAndCode(t *testing.T) {
+	nodes := parseInline("[a](https://x.com) and https://x.com")
+	if len(nodes) != 3 || nodes[0].Type != NodeLink || nodes[2].Type != NodeLink {
+		t.Fatalf("Expected link, text, link, got %+v", nodes)
+	}
+	if nodes[0].Content != "a" || len(nodes[0].Children) != 1 || nodes[0].Children[0].Type != NodeText {
+		t.Errorf("Expected the explicit link text to be left alone, got %+v", nodes[0].Children)
+	}
+
+	nodes = parseInline("`https://x.com`")
+	if len(nodes) != 1 || nodes[0].Type != NodeStyle || nodes[0].Children[0].Type != NodeText {
+		t.Errorf("Expected a URL inside a code span to stay plain, got %+v", nodes)
+	}
+}
+
+// inlineTokenReference is the regular expression parseInline used before the
+// hand-written tokenizer, anchored to match at one position. Groups 1-4 are the
+// flanked delimiters, which also need no word character before the opener.
+var inlineTokenReference = regexp.MustCompile(`^(?:` +
+	`(--[^\s-](?:.*?[^\s-])??--)(?:[^\p{L}\p{Nd}_]|$)|` +
+	`(::[^\s:](?:.*?[^\s:])??::)(?:[^\p{L}\p{Nd}_]|$)|` +
+	`(!![^\s!](?:.*?[^\s!])??!!)(?:[^\p{L}\p{Nd}_]|$)|` +
+	`(\?\?[^\s?](?:.*?[^\s?])??\?\?)(?:[^\p{L}\p{Nd}_]|$)|` +
+	"((`[^`]+`)|" + `(\\[!-/:-@\[-` + "`" + `{-~])|(%v|%\{[a-zA-Z_][a-zA-Z0-9_]*\})|(\*\*.+?\*\*)|(__.+?__)|(~~.+?~~)|(\^[^^\s]+\^)|(~[^~\s]+~)|(!?\[[^\]]*\](?:\([^)\s]*(?:\s+"[^"]*")?\)|\[[^\]]*\]))|(!?#[a-zA-Z0-9]{3,16}\(.+?\))))`)
+
+// referenceTokens finds tokens left to right with inlineTokenReference
+func referenceTokens(in string) [][2]int {
+	var out [][2]int
+	for i := 0; i < len(in); {
+		m := inlineTokenReference.FindStringSubmatchIndex(in[i:])
+		start, end := -1, -1
+		for g := 1; m != nil && g <= 5; g++ {
+			if m[2*g] >= 0 {
+				start, end = m[2*g], m[2*g+1]
+				if g <= 4 {
+					if r, _ := utf8.DecodeLastRuneInString(in[:i]); i > 0 && isWordRune(r) {
+						start = -1
+					}
+				}
+				break
+			}
+		}
+		if start < 0 {
+			i++
+			continue
+		}
+		out = append(out, [2]int{i + start, i + end})
+		i += end
+	}
+	return out
+}
+
+func TestInlineTokenizerMatchesReference(t *testing.T) {
+	inputs := []string{
+		"plain text",
+		"**bold** and __under__ and ~~strike~~",
+		"***", "****", "*****", "** **", "**\n**", "__a__b__",
+		"`code` and ``x` and `unclosed",
+		`\*esc\* \% \a \\`,
+		"%v %{name} %{1bad} %{ok_1} %{open %",
+		"[a](b) [a](b \"t\") [a](b  \"t\" ) [a](b c) [a][r] [a][] ![i](s) ![i][r] [a] b]",
+		"#red(x) !#blue(y) #ab(c) #abcdefghi(d) #red() #red(a\nb)",
+		"![x #red(y)](u) !#red[x] !!# !",
+		"mixed **#red(a)** `**`",
+		"--dim-- ::blink:: !!rev!! ??hid?? !!#red(x) !![a](b)!! a -- b",
+		"--color=always or --verbose, std::vector and std::map, Really?? Yes??",
+		"!!!!!! ???? ,,  -- --- a--b--c --x--y ::é:: é::x::",
+		"19^th^ H~2~O ~~x~~ ~a~~b~~ ^ a^ ~~~x~ ^^ ~\n~",
+	}
+
+	// Plus every short string over the markup alphabet
+	alphabet := []string{"*", "_", "~", "`", "\\", "%", "v", "{", "}", "[", "]", "(", ")", "!", "#", "red", " ", "\"", "\n", "a", "-", ":", "?", "^"}
+	var gen func(prefix string, depth int)
+	gen = func(prefix string, depth int) {
+		inputs = append(inputs, prefix)
+		if depth == 0 {
+			return
+		}
+		for _, s := range alphabet {
+			gen(prefix+s, depth-1)
+		}
+	}
+	gen("", 4)
+
+	for _, in := range inputs {
+		want := referenceTokens(in)
+		tk := inlineTokenizer{text: in}
+		got := tk.tokens()
+		if len(got) != len(want) {
+			t.Fatalf("%q: expected tokens %v, got %v", in, want, got)
+		}
+		for i := range want {
+			if got[i][0] != want[i][0] || got[i][1] != want[i][1] {
+				t.Fatalf("%q: expected tokens %v, got %v", in, want, got)
+			}
+		}
+	}
+}
+
+func BenchmarkParseInlineStars(b *testing.B) {
+	line := strings.Repeat("*", 10*1024)
+	for i := 0; i < b.N; i++ {
+		parseInline(line)
+	}
+}
+
+func BenchmarkParseInlineLinkOpeners(b *testing.B) {
+	line := strings.Repeat("[](", 10*1024)
+	for i := 0; i < b.N; i++ {
+		parseInline(line)
+	}
+}
+
+func BenchmarkParseInlineUnmatched(b *testing.B) {
+	line := strings.Repeat("**a [b #red(", 1024)
+	for i := 0; i < b.N; i++ {
+		parseInline(line)
+	}
+}
@@ -1,5 +1,11 @@
 package basement
 
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
 // Style represents the visual style of a cell
 type Style struct {
 	Bold      bool
@@ -9,22 +15,110 @@ type Style struct {
 	Strike    bool // Added Strike
 	Reverse   bool
 	Blink     bool
+	Hidden    bool
 	Color     string // ANSI color code
 	BgColor   string // ANSI background color code
 }
 
-// GetColorCode returns the ANSI escape code for a given color name
+// Named colors for #name(text) markup
+var (
+	colorMu sync.RWMutex
+	colors  = map[string]string{
+		"black":   "\x1b[30m",
+		"red":     "\x1b[31m",
+		"green":   "\x1b[32m",
+		"blue":    "\x1b[34m",
+		"magenta": "\x1b[35m",
+		"cyan":    "\x1b[36m",
+		"white":   "\x1b[37m",
+		"yellow":  "\x1b[33m",
+		"grey":    "\x1b[90m",
+
+		"brightblack":   "\x1b[90m",
+		"brightred":     "\x1b[91m",
+		"brightgreen":   "\x1b[92m",
+		"brightyellow":  "\x1b[93m",
+		"brightblue":    "\x1b[94m",
+		"brightmagenta": "\x1b[95m",
+		"brightcyan":    "\x1b[96m",
+		"brightwhite":   "\x1b[97m",
+
+		// The terminal's own foreground
+		"default": "\x1b[39m",
+	}
+
+	// Other names for colors, resolved when looked up so they follow
+	// RegisterColor overrides of the color they stand for
+	colorAliases = map[string]string{
+		"gray":   "grey",
+		"purple": "magenta",
+		"reset":  "default",
+	}
+)
+
+// RegisterColor defines or overrides a named color, e.g.
+// RegisterColor("brand", "\x1b[38;5;208m") enables #brand(text).
+// Names are 3-16 ASCII letters or digits, as markup can't refer to any other;
+// an invalid name is reported and not registered. Register colors at startup:
+// tui caches parsed templates, which keep the codes they were parsed with.
+func RegisterColor(name, ansiCode string) error {
+	if !validColorName(name) {
+		return fmt.Errorf("basement: invalid color name %q: want 3-16 letters or digits", name)
+	}
+	colorMu.Lock()
+	defer colorMu.Unlock()
+	colors[name] = ansiCode
+	return nil
+}
+
+// validColorName reports whether name can be used in #name(text)
+func validColorName(name string) bool {
+	if len(name) < 3 || len(name) > 16 {
+		return false
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// GetColorCode returns the ANSI escape code for a given color name or alias
+// (gray, purple, reset), or "" if the name isn't registered
 func GetColorCode(name string) string {
-	switch name {
-	case "black":   return "\x1b[30m"
-	case "red":     return "\x1b[31m"
-	case "green":   return "\x1b[32m"
-	case "blue":    return "\x1b[34m"
-	case "magenta": return "\x1b[35m"
-	case "cyan":    return "\x1b[36m"
-	case "white":   return "\x1b[37m"
-	case "yellow":  return "\x1b[33m"
-	case "grey":    return "\x1b[90m"
-	default:        return ""
+	colorMu.RLock()
+	defer colorMu.RUnlock()
+	if code, ok := colors[name]; ok {
+		return code
+	}
+	return colors[colorAliases[name]]
+}
+
+// GetBgColorCode returns the ANSI escape code that sets the named color as the
+// background, as used by !#name(text), or "" if the name isn't registered
+func GetBgColorCode(name string) string {
+	return backgroundCode(GetColorCode(name))
+}
+
+// backgroundCode turns a foreground SGR escape into the matching background
+// one: 3x to 4x, 9x to 10x, 38;... to 48;... Other escapes are kept as they
+// are, so a registered code that is already a background still works.
+func backgroundCode(fg string) string {
+	if !strings.HasPrefix(fg, "\x1b[") || !strings.HasSuffix(fg, "m") {
+		return fg
+	}
+	params := fg[2 : len(fg)-1]
+	if len(params) < 2 {
+		return fg
+	}
+	switch params[0] {
+	case '3':
+		return "\x1b[4" + params[1:] + "m"
+	case '9':
+		if len(params) == 2 {
+			return "\x1b[10" + params[1:] + "m"
+		}
 	}
+	return fg
 }
@@ -0,0 +1,323 @@
+package basement
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// inlineTokenizer finds the markup tokens in a line of text in a single
+// left-to-right pass. At each position it tries, in order:
+//
+//	`code`                      code span (content is literal)
+//	\*                          backslash before ASCII punctuation
+//	%v  %{name}                 holes
+//	**bold**  __under__  ~~strike~~
+//	--dim--  ::blink::  !!reverse!!  ??hidden??  (flanked, see flanked)
+//	^super^  ~sub~
+//	[text](url "title")  [text][id]  ![alt](src)
+//	#color(text)  !#color(text)
+//
+// Searches for closing delimiters are memoized, so unmatched openers (a long
+// run of "*" or "[") cost linear time rather than rescanning the rest of the
+// line from every position.
+type inlineTokenizer struct {
+	text string
+
+	// Next occurrence of each delimiter, see nextIndex
+	backtick, newline, closeBracket, closeParen nextIndex
+	stars, unders, tildes                       nextIndex
+	dashes, colons, bangs, questions            nextIndex
+	urlEnd, titleStart, titleEnd                nextIndex
+}
+
+// nextIndex memoizes the first occurrence of a substring at or after a position
+type nextIndex struct {
+	from, at int // Searching from `from` found `at` (-1: none)
+	valid    bool
+}
+
+// find returns the index of the first sub at or after from, or -1
+func (n *nextIndex) find(text, sub string, from int) int {
+	// Earlier result still applies if from lies between that search and its hit
+	if n.valid && from >= n.from && (n.at < 0 || from <= n.at) {
+		return n.at
+	}
+	n.from, n.valid = from, true
+	n.at = -1
+	if from <= len(text) {
+		if i := strings.Index(text[from:], sub); i >= 0 {
+			n.at = from + i
+		}
+	}
+	return n.at
+}
+
+// findByte is find for the first byte that match accepts
+func (n *nextIndex) findByte(text string, from int, match func(b byte) bool) int {
+	if n.valid && from >= n.from && (n.at < 0 || from <= n.at) {
+		return n.at
+	}
+	n.from, n.valid = from, true
+	n.at = -1
+	for i := from; i < len(text); i++ {
+		if match(text[i]) {
+			n.at = i
+			break
+		}
+	}
+	return n.at
+}
+
+// findValid is find for the first sub at or after from that valid accepts.
+// valid must depend only on the position, so the memoized hit stays right.
+func (n *nextIndex) findValid(text, sub string, from int, valid func(at int) bool) int {
+	if n.valid && from >= n.from && (n.at < 0 || from <= n.at) {
+		return n.at
+	}
+	n.from, n.valid = from, true
+	n.at = -1
+	for from <= len(text) {
+		i := strings.Index(text[from:], sub)
+		if i < 0 {
+			break
+		}
+		if valid(from + i) {
+			n.at = from + i
+			break
+		}
+		from += i + 1
+	}
+	return n.at
+}
+
+// tokens returns the [start, end) byte offsets of every token in text
+func (t *inlineTokenizer) tokens() [][2]int {
+	var out [][2]int
+	for i := 0; i < len(t.text); {
+		if end := t.match(i); end > i {
+			out = append(out, [2]int{i, end})
+			i = end
+		} else {
+			i++
+		}
+	}
+	return out
+}
+
+// match returns the end of the token starting at i, or -1
+func (t *inlineTokenizer) match(i int) int {
+	s := t.text
+	switch s[i] {
+	case '`':
+		// At least one character between the backticks
+		if j := t.backtick.find(s, "`", i+1); j > i+1 {
+			return j + 1
+		}
+	case '\\':
+		if i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			return i + 2
+		}
+	case '%':
+		return t.hole(i)
+	case '*':
+		return t.delimited(i, "**", &t.stars)
+	case '_':
+		return t.delimited(i, "__", &t.unders)
+	case '~':
+		if end := t.delimited(i, "~~", &t.tildes); end > i {
+			return end
+		}
+		return t.script(i, '~')
+	case '^':
+		return t.script(i, '^')
+	case '-':
+		return t.flanked(i, "--", &t.dashes)
+	case ':':
+		return t.flanked(i, "::", &t.colons)
+	case '?':
+		return t.flanked(i, "??", &t.questions)
+	case '[':
+		return t.link(i)
+	case '#':
+		return t.color(i)
+	case '!':
+		if i+1 < len(s) {
+			switch s[i+1] {
+			case '[':
+				return t.link(i + 1)
+			case '#':
+				return t.color(i + 1)
+			case '!':
+				return t.flanked(i, "!!", &t.bangs)
+			}
+		}
+	}
+	return -1
+}
+
+// hole matches %v or %{name}
+func (t *inlineTokenizer) hole(i int) int {
+	s := t.text
+	if i+1 >= len(s) {
+		return -1
+	}
+	if s[i+1] == 'v' {
+		return i + 2
+	}
+	if s[i+1] != '{' || i+2 >= len(s) || !isIdentStart(s[i+2]) {
+		return -1
+	}
+	j := i + 3
+	for j < len(s) && isIdentPart(s[j]) {
+		j++
+	}
+	if j < len(s) && s[j] == '}' {
+		return j + 1
+	}
+	return -1
+}
+
+// delimited matches delim, at least one character, then the nearest delim,
+// all on one line
+func (t *inlineTokenizer) delimited(i int, delim string, next *nextIndex) int {
+	if !strings.HasPrefix(t.text[i:], delim) {
+		return -1
+	}
+	closing := next.find(t.text, delim, i+len(delim)+1)
+	if closing < 0 || t.crossesLine(i, closing) {
+		return -1
+	}
+	return closing + len(delim)
+}
+
+// flanked matches delim, text, delim like delimited, but only where the
+// delimiters hug a word, so that prose keeps its punctuation: "--verbose",
+// "std::map", "Really??" and "a -- b" are not styled. The opener must not
+// follow a word character and must be followed by a character that is
+// neither space nor part of the delimiter; the closer mirrors that.
+func (t *inlineTokenizer) flanked(i int, delim string, next *nextIndex) int {
+	s := t.text
+	if !strings.HasPrefix(s[i:], delim) || i+len(delim) >= len(s) ||
+		t.wordBefore(i) || !t.hugs(s[i+len(delim)], delim) {
+		return -1
+	}
+	closing := next.findValid(s, delim, i+len(delim)+1, func(j int) bool {
+		return t.hugs(s[j-1], delim) && !t.wordAfter(j+len(delim))
+	})
+	if closing < 0 || t.crossesLine(i, closing) {
+		return -1
+	}
+	return closing + len(delim)
+}
+
+// hugs reports whether b, just inside a flanked delimiter, lets it open or close
+func (t *inlineTokenizer) hugs(b byte, delim string) bool {
+	return !isSpace(b) && b != delim[0]
+}
+
+// wordBefore reports whether the character ending at i is a letter, digit or "_"
+func (t *inlineTokenizer) wordBefore(i int) bool {
+	r, _ := utf8.DecodeLastRuneInString(t.text[:i])
+	return i > 0 && isWordRune(r)
+}
+
+// wordAfter reports whether the character starting at i is a letter, digit or "_"
+func (t *inlineTokenizer) wordAfter(i int) bool {
+	r, _ := utf8.DecodeRuneInString(t.text[i:])
+	return i < len(t.text) && isWordRune(r)
+}
+
+// script matches a super- or subscript: delim, one or more characters that
+// are neither delim nor whitespace, then delim
+func (t *inlineTokenizer) script(i int, delim byte) int {
+	s := t.text
+	j := i + 1
+	for j < len(s) && s[j] != delim && !isSpace(s[j]) {
+		j++
+	}
+	if j == i+1 || j == len(s) || s[j] != delim {
+		return -1
+	}
+	return j + 1
+}
+
+// link matches [text](url "title") or [text][id]; i is at the "["
+func (t *inlineTokenizer) link(i int) int {
+	s := t.text
+	c := t.closeBracket.find(s, "]", i+1)
+	if c < 0 || c+1 >= len(s) {
+		return -1
+	}
+
+	j := c + 2
+	switch s[c+1] {
+	case '[':
+		if end := t.closeBracket.find(s, "]", j); end >= 0 {
+			return end + 1
+		}
+	case '(':
+		// URL: anything up to ")" or whitespace
+		j = t.urlEnd.findByte(s, j, func(b byte) bool { return b == ')' || isSpace(b) })
+		if j < 0 {
+			return -1
+		}
+		if s[j] == ')' {
+			return j + 1
+		}
+		// Optional "title" after whitespace
+		j = t.titleStart.findByte(s, j, func(b byte) bool { return !isSpace(b) })
+		if j < 0 || s[j] != '"' {
+			return -1
+		}
+		if q := t.titleEnd.find(s, `"`, j+1); q >= 0 {
+			j = q + 1
+			if j < len(s) && s[j] == ')' {
+				return j + 1
+			}
+		}
+	}
+	return -1
+}
+
+// color matches #name(text) with a 3-16 character name; i is at the "#"
+func (t *inlineTokenizer) color(i int) int {
+	s := t.text
+	j := i + 1
+	for j < len(s) && isAlnum(s[j]) {
+		j++
+	}
+	if n := j - i - 1; n < 3 || n > 16 || j >= len(s) || s[j] != '(' {
+		return -1
+	}
+	closing := t.closeParen.find(s, ")", j+2)
+	if closing < 0 || t.crossesLine(j, closing) {
+		return -1
+	}
+	return closing + 1
+}
+
+// crossesLine reports whether there is a newline between from and to
+func (t *inlineTokenizer) crossesLine(from, to int) bool {
+	nl := t.newline.find(t.text, "\n", from)
+	return nl >= 0 && nl < to
+}
+
+func isASCIIPunct(b byte) bool {
+	return b >= '!' && b <= '/' || b >= ':' && b <= '@' || b >= '[' && b <= '`' || b >= '{' && b <= '~'
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\f' || b == '\r'
+}
+
+func isAlnum(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+func isIdentStart(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b == '_'
+}
+
+func isIdentPart(b byte) bool {
+	return isIdentStart(b) || b >= '0' && b <= '9'
+}
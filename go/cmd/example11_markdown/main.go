@@ -1,7 +1,6 @@
 package main
 
 import (
-	"basement/signals"
 	"basement/tui"
 )
 
@@ -65,7 +64,7 @@ __This is bold text__ (Rendered as Underline in BasementUI)
 
 _This is italic text_ (Not supported yet)
 
-~~Strikethrough~~ (Not supported yet)
+~~Strikethrough~~
 
 
 ## Blockquotes
@@ -259,63 +258,27 @@ It converts "HTML", but keep intact partial entries like "xxxHTMLyyy" and so on.
 *here be dragons*
 :::
 
-(Press 'q' or Ctrl+C to exit. Use Up/Down to scroll.)
+(Press 'q' or Ctrl+C to exit. Use Up/Down, PgUp/PgDown, Home/End to scroll.)
 `
 
-	// Reactive scroll state
-	scrollY := signals.New(0)
-
 	app := func() tui.Renderable {
-		// We can't easily pass scrollY to Template directly as a prop for the whole view
-		// because Template parses the string.
-		// But Render() now uses screen.ScrollY.
-		// So we just need to update screen.ScrollY when the signal changes.
-		// Wait, Render() reads screen.ScrollY directly.
-		// But screen.ScrollY is not a signal.
-		// We need to bind the signal to the screen property or update it manually.
-
 		return tui.Template(markdown)
 	}
 
 	screen := tui.NewScreen()
 	defer screen.Close()
 
-	// Create an effect to sync signal to screen
-	signals.CreateEffect(func() {
-		screen.ScrollY = scrollY.Get()
-		// Note: This effect is necessary to update the screen state reactively.
-		// The render effect (created by tui.Render) depends on signals accessed within it.
-		// Since wrappedApp accesses scrollY.Get(), the render effect will re-run when scrollY changes.
-		// However, we also need to ensure screen.ScrollY is updated *before* the render logic runs.
-		// Because effects run in creation order, and this effect is created before tui.Render,
-		// this runs first, updates the screen state, and then the render effect runs with the new state.
-	})
-
-	// To make it reactive, we need to access scrollY inside the Render loop.
-	// We can wrap the app function.
+	// screen.ScrollY is a signal read by Render, so scrolling redraws automatically
+	tui.Render(screen, app)
 
-	wrappedApp := func() tui.Renderable {
-		scrollY.Get() // Register dependency so render effect re-runs on scroll
-		return app()
-	}
-
-	tui.Render(screen, wrappedApp)
-
-	// Handle Input
-	quit := make(chan bool)
-	screen.OnKey(func(ev tui.KeyEvent) {
+	// Handle keys until exit
+	screen.Run(func(ev tui.KeyEvent) bool {
 		if ev.Rune == 'q' || (ev.Key == tui.KeyChar && ev.Mod == tui.ModCtrl && ev.Rune == 'c') {
-			quit <- true
+			return true
 		}
 
-		if ev.Key == tui.KeyArrowDown {
-			scrollY.Set(scrollY.Get() + 1)
-		} else if ev.Key == tui.KeyArrowUp {
-			val := scrollY.Get()
-			if val > 0 {
-				scrollY.Set(val - 1)
-			}
-		}
+		// Arrows, PgUp/PgDown and Home/End, stopping at the end of the document
+		screen.ScrollKey(ev, screen.ContentHeight().Peek())
+		return false
 	})
-	<-quit
 }
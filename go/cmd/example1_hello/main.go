@@ -25,9 +25,7 @@ You can use *italics*, __underline__, and even #green(colors)!
 	tui.Render(screen, app)
 
 	// Wait for any key to exit
-	quit := make(chan bool)
-	screen.OnKey(func(ev tui.KeyEvent) {
-		quit <- true
+	screen.Run(func(ev tui.KeyEvent) bool {
+		return true
 	})
-	<-quit
 }
@@ -41,12 +41,8 @@ Double: %v
 		}
 	}()
 
-	// Wait for exit signal
-	quit := make(chan bool)
-	screen.OnKey(func(ev tui.KeyEvent) {
-		if ev.Rune == 'q' || (ev.Key == tui.KeyChar && ev.Mod == tui.ModCtrl && ev.Rune == 'c') {
-			quit <- true
-		}
+	// Handle keys until exit
+	screen.Run(func(ev tui.KeyEvent) bool {
+		return ev.Rune == 'q' || (ev.Key == tui.KeyChar && ev.Mod == tui.ModCtrl && ev.Rune == 'c')
 	})
-	<-quit
 }
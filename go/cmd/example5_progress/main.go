@@ -3,35 +3,24 @@ package main
 import (
 	"basement/signals"
 	"basement/tui"
-	"strings"
 	"time"
 )
 
 func main() {
 	// Example 5: Progress Bar
-	// Shows how to build a custom component (progress bar) using Computed values.
+	// Shows the reusable ProgressBar component, a Computed value bound to a signal.
 
 	progress := signals.New(0)
 
-	// Create a computed signal that returns the visual representation of the bar
-	bar := signals.NewComputed(func() string {
-		p := progress.Get()
-		width := 20
-		filled := int(float64(p) / 100.0 * float64(width))
-		if filled > width {
-			filled = width
-		}
-		empty := width - filled
-
-		return "[" + strings.Repeat("#", filled) + strings.Repeat("-", empty) + "]"
-	})
+	// ProgressBar returns a computed signal holding the visual representation of the bar
+	bar := tui.ProgressBar(progress, 20, tui.WithProgressRunes('#', '-'))
 
 	app := func() tui.Renderable {
 		return tui.Template(`
 # Task Progress
 
 Downloading...
-%v  **%v%%**
+[%v]  **%v%%**
 
 (Press 'q' or Ctrl+C to exit)
 `, bar, progress)
@@ -49,12 +38,8 @@ Downloading...
 		}
 	}()
 
-	// Wait for exit signal
-	quit := make(chan bool)
-	screen.OnKey(func(ev tui.KeyEvent) {
-		if ev.Rune == 'q' || (ev.Key == tui.KeyChar && ev.Mod == tui.ModCtrl && ev.Rune == 'c') {
-			quit <- true
-		}
+	// Handle keys until exit
+	screen.Run(func(ev tui.KeyEvent) bool {
+		return ev.Rune == 'q' || (ev.Key == tui.KeyChar && ev.Mod == tui.ModCtrl && ev.Rune == 'c')
 	})
-	<-quit
 }
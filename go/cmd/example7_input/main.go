@@ -14,16 +14,11 @@ func main() {
 	y := signals.New(5)
 	msg := signals.New("Press Arrow Keys to move, 'q' to quit")
 
-	// Create a computed signal for the character position
-	// We render the whole screen content based on x/y
-	view := signals.NewComputed(func() string {
-		// This is a bit inefficient (rebuilding the whole string), but works for demo.
-		// In a real app, we'd use layout nodes.
-		// For now, we just return a string that positions the character.
-
+	// Combine x and y into a single computed signal for the character position
+	view := signals.Combine2(x, y, func(x, y int) string {
 		// We can't easily position absolute text in the template string without layout support.
 		// So we'll just show the coordinates.
-		return fmt.Sprintf("Position: (%d, %d)", x.Get(), y.Get())
+		return fmt.Sprintf("Position: (%d, %d)", x, y)
 	})
 
 	app := func() tui.Renderable {
@@ -49,10 +44,8 @@ func main() {
 
 	tui.Render(screen, app)
 
-	// Handle Input
-	quit := make(chan bool)
-
-	screen.OnKey(func(ev tui.KeyEvent) {
+	// Handle keys until exit
+	screen.Run(func(ev tui.KeyEvent) bool {
 		switch ev.Key {
 		case tui.KeyArrowUp:
 			y.Set(y.Get() - 1)
@@ -67,11 +60,8 @@ func main() {
 			x.Set(x.Get() + 1)
 			msg.Set("Moved Right")
 		case tui.KeyChar:
-			if ev.Rune == 'q' || (ev.Mod == tui.ModCtrl && ev.Rune == 'c') {
-				quit <- true
-			}
+			return ev.Rune == 'q' || (ev.Mod == tui.ModCtrl && ev.Rune == 'c')
 		}
+		return false
 	})
-
-	<-quit
 }
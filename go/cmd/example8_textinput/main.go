@@ -1,26 +1,15 @@
 package main
 
 import (
-	"basement/signals"
 	"basement/tui"
 )
 
 func main() {
 	// Example 8: Text Input
-	// A simple text field implementation.
-	// Demonstrates combining input handling with reactive state to build controls.
+	// A text field built on the TextInput widget.
+	// Demonstrates forwarding key events to a control that owns its own reactive state.
 
-	input := signals.New("")
-
-	// Computed view for the input field
-	field := signals.NewComputed(func() string {
-		txt := input.Get()
-
-		// Add a static cursor at the end
-		cursorChar := "#white(! !)" // Reverse space (block cursor)
-
-		return "#blue(> )" + txt + cursorChar
-	})
+	input := tui.NewTextInput().WithPlaceholder("type here")
 
 	app := func() tui.Renderable {
 		return tui.Template(`
@@ -28,10 +17,10 @@ func main() {
 
 Type something below:
 
-%v
+#blue(>) %v
 
 (Press 'Esc' or Ctrl+C to quit)
-`, field)
+`, input)
 	}
 
 	screen := tui.NewScreen()
@@ -39,32 +28,13 @@ Type something below:
 
 	tui.Render(screen, app)
 
-	// Handle Input
-	quit := make(chan bool)
-
-	screen.OnKey(func(ev tui.KeyEvent) {
-		current := input.Get()
-
-		switch ev.Key {
-		case tui.KeyChar:
-			// Handle Ctrl+C
-			if ev.Mod == tui.ModCtrl && ev.Rune == 'c' {
-				quit <- true
-				return
-			}
-			input.Set(current + string(ev.Rune))
-		case tui.KeySpace:
-			input.Set(current + " ")
-		case tui.KeyBackspace:
-			// Note: This is a simple byte-slicing backspace.
-			// For full unicode support, we should convert to []rune, slice, then back to string.
-			if len(current) > 0 {
-				input.Set(current[:len(current)-1])
-			}
-		case tui.KeyEsc:
-			quit <- true
+	// Handle keys until exit
+	screen.Run(func(ev tui.KeyEvent) bool {
+		if ev.Key == tui.KeyEsc || (ev.Key == tui.KeyChar && ev.Mod == tui.ModCtrl && ev.Rune == 'c') {
+			return true
 		}
+		// Characters, Backspace, Delete, Left/Right, Home/End
+		input.HandleKey(ev)
+		return false
 	})
-
-	<-quit
 }
@@ -4,10 +4,8 @@ go 1.18
 
 require (
 	github.com/alecthomas/chroma v0.10.0
+	golang.org/x/sys v0.18.0
 	golang.org/x/term v0.18.0
 )
 
-require (
-	github.com/dlclark/regexp2 v1.4.0 // indirect
-	golang.org/x/sys v0.18.0 // indirect
-)
+require github.com/dlclark/regexp2 v1.4.0 // indirect
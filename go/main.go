@@ -12,28 +12,115 @@ import (
 func main() {
 	info, err := os.Stdin.Stat()
 
-	if len(os.Args) > 1 {
-		if os.Args[1] == "-h" || os.Args[1] == "--help" {
+	args, mode := colorFlag(os.Args[1:])
+	if mode != "always" && mode != "never" && mode != "auto" {
+		fmt.Fprintf(os.Stderr, "Invalid --color value %q (want always, never or auto)\n", mode)
+		os.Exit(2)
+	}
+	color := colorEnabled(mode)
+
+	if len(args) > 0 {
+		if args[0] == "-h" || args[0] == "--help" {
 			demo()
 			return
 		}
-		input := strings.Join(os.Args[1:], " ")
-		fmt.Println(basement.Parse(input))
+		// Arguments are one line of input, newline included, so the output
+		// ends the same way as for piped input
+		input := strings.Join(args, " ") + "\n"
+		fmt.Print(basement.ParseAuto(input, color))
 	} else if err == nil && (info.Mode() & os.ModeCharDevice) == 0 {
-		reader := bufio.NewReader(os.Stdin)
-		var builder strings.Builder
-		for {
-			line, err := reader.ReadString('\n')
-			builder.WriteString(line)
-			if err == io.EOF {
-				break
-			}
+		if err := stream(os.Stdin, os.Stdout, color); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+			os.Exit(1)
 		}
-		input := builder.String()
-		fmt.Print(basement.Parse(input))
 	} else {
-		fmt.Fprintln(os.Stderr, "Usage: basement <markdown> or pipe input")
+		fmt.Fprintln(os.Stderr, "Usage: basement [--color=always|never|auto] <markdown> or pipe input")
+	}
+}
+
+// stream parses r paragraph by paragraph, writing each one as soon as it is
+// complete, so large inputs and pipelines don't wait for EOF. A paragraph
+// ends at a blank line, unless a code span or block is still open.
+func stream(r io.Reader, w io.Writer, color bool) error {
+	reader := bufio.NewReader(r)
+	out := bufio.NewWriter(w)
+	var block strings.Builder
+
+	flush := func() error {
+		out.WriteString(basement.ParseAuto(block.String(), color))
+		block.Reset()
+		return out.Flush()
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		block.WriteString(line)
+		if err == io.EOF {
+			return flush()
+		}
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(line) == "" && codeClosed(block.String()) {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// codeClosed reports whether every run of backticks in s has a partner of the
+// same length, i.e. no code span or block is left open.
+func codeClosed(s string) bool {
+	open := map[int]bool{}
+	for i := 0; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(s) && s[j] == '`' {
+			j++
+		}
+		open[j-i] = !open[j-i]
+		i = j
+	}
+	for _, o := range open {
+		if o {
+			return false
+		}
+	}
+	return true
+}
+
+// colorFlag extracts a --color=<mode> flag from args, defaulting to "auto".
+func colorFlag(args []string) ([]string, string) {
+	mode := "auto"
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--color=") {
+			mode = strings.TrimPrefix(arg, "--color=")
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, mode
+}
+
+// colorEnabled decides whether to emit escapes. "auto" honours NO_COLOR
+// (https://no-color.org) and only colors output going to a terminal.
+func colorEnabled(mode string) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
 	}
+	info, err := os.Stdout.Stat()
+	return err == nil && (info.Mode()&os.ModeCharDevice) != 0
 }
 
 func demo() {
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"basement/basement"
 	"bytes"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -37,3 +39,70 @@ func TestBasementOutput(t *testing.T) {
 		t.Errorf("Output mismatch.\nExpected length: %d\nActual length: %d\nSee actual_output.txt for details.", len(expected), len(actual))
 	}
 }
+
+func TestColorDisabled(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	if colorEnabled("auto") {
+		t.Errorf("Expected NO_COLOR to disable color in auto mode")
+	}
+	if !colorEnabled("always") {
+		t.Errorf("Expected --color=always to override NO_COLOR")
+	}
+
+	args, mode := colorFlag([]string{"--color=never", "**hi**", "#red(x)"})
+	if mode != "never" || len(args) != 2 {
+		t.Fatalf("Flag parsing failed: mode=%q args=%v", mode, args)
+	}
+
+	out := basement.ParseAuto(strings.Join(args, " "), colorEnabled(mode))
+	if strings.Contains(out, "\x1b") {
+		t.Errorf("Expected no escape codes with color disabled, got %q", out)
+	}
+}
+
+// chunkWriter records each write separately
+type chunkWriter struct {
+	chunks []string
+}
+
+func (c *chunkWriter) Write(p []byte) (int, error) {
+	c.chunks = append(c.chunks, string(p))
+	return len(p), nil
+}
+
+func TestStreamParagraphs(t *testing.T) {
+	var in strings.Builder
+	for i := 0; i < 500; i++ {
+		in.WriteString("# Section\nSome *bold* and #red(red) text.\n  * item\n\n")
+	}
+	// A code block with a blank line inside must not be split
+	in.WriteString("```\ncode *not bold*\n\nstill code\n```\n\nlast line")
+
+	var out chunkWriter
+	if err := stream(strings.NewReader(in.String()), &out, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out.chunks) != 502 {
+		t.Errorf("Expected one write per paragraph (502), got %d", len(out.chunks))
+	}
+	if got, want := strings.Join(out.chunks, ""), basement.Parse(in.String()); got != want {
+		t.Errorf("Streamed output differs from parsing the whole input")
+	}
+	if code := out.chunks[500]; !strings.Contains(code, "*not bold*\n\nstill code") {
+		t.Errorf("Expected the code block in one piece, got %q", code)
+	}
+}
+
+func TestStreamKeepsTrailingNewline(t *testing.T) {
+	for _, in := range []string{"# a\n\nlast", "# a\n\nlast\n"} {
+		var out strings.Builder
+		if err := stream(strings.NewReader(in), &out, false); err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasSuffix(out.String(), "\n") != strings.HasSuffix(in, "\n") {
+			t.Errorf("%q: expected the trailing newline kept as is, got %q", in, out.String())
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package signals
 
 import (
+	"runtime"
 	"sync"
+	"sync/atomic"
 )
 
 // Getter is a type-erased interface for Signals
@@ -19,6 +21,9 @@ type Signal[T any] struct {
 	value       T
 	subscribers []Subscriber
 	mu          sync.RWMutex
+	owner       *Effect // The effect computing this signal, nil for plain signals
+	equals      func(a, b T) bool
+	panicked    interface{} // What owner last panicked with, re-raised by reads
 }
 
 // New creates a new Signal with an initial value
@@ -28,37 +33,73 @@ func New[T any](val T) *Signal[T] {
 	}
 }
 
+// NewWithEquals creates a Signal that uses eq to decide whether Set changes
+// the value. Use it for types == can't compare (structs holding slices or
+// funcs), where the default check would notify on every Set.
+func NewWithEquals[T any](val T, eq func(a, b T) bool) *Signal[T] {
+	return &Signal[T]{
+		value:  val,
+		equals: eq,
+	}
+}
+
 // GetValue implements the Getter interface
 func (s *Signal[T]) GetValue() interface{} {
 	return s.Get()
 }
 
-// Get returns the current value and tracks dependency if called within an Effect
+// Get returns the current value and tracks dependency if called within an Effect.
+// Called on another goroutine while an effect is running, Get is tracked by
+// that effect; use Peek to read outside effects.
 func (s *Signal[T]) Get() T {
-	// We need to be careful with locking order.
-	// First, capture the active effect if any.
-	// Accessing the global activeEffect is technically a race if multiple goroutines
-	// are running effects. For this MVP, we assume UI effects run on the main thread.
-	effect := activeEffect
+	// A root owns what is created inside it but tracks nothing
+	effect := currentEffect()
+	track := effect != nil && !effect.root
 
-	if effect != nil {
+	// A computed that went to sleep catches up before it is read
+	if s.owner != nil {
+		s.owner.wake(track)
+	}
+
+	if track {
 		s.subscribe(effect)
+		effect.addSource(s, s.level())
 	}
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.value
+	return s.read()
 }
 
 // Peek returns the current value without tracking dependency
 func (s *Signal[T]) Peek() T {
+	if s.owner != nil {
+		s.owner.wake(false)
+	}
+	return s.read()
+}
+
+// read returns the value, or panics again with what the computing effect
+// panicked with, so the panic reaches the reader rather than the Set that
+// made the computed recompute
+func (s *Signal[T]) read() T {
 	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.value
+	v, p := s.value, s.panicked
+	s.mu.RUnlock()
+	if p != nil {
+		panic(p)
+	}
+	return v
 }
 
 // Set updates the value and notifies subscribers
 func (s *Signal[T]) Set(val T) {
+	if subs := s.swap(val); subs != nil {
+		notify(subs)
+	}
+}
+
+// swap updates the value and returns the subscribers to notify, nil if the
+// value didn't change or nothing is subscribed
+func (s *Signal[T]) swap(val T) []Subscriber {
 	s.mu.Lock()
 
 	// Fast equality check using interface comparison.
@@ -66,20 +107,55 @@ func (s *Signal[T]) Set(val T) {
 	// For non-comparable types (structs with slices, linked lists), the recover
 	// skips the check and always propagates — safe and avoids the catastrophic
 	// cost of reflect.DeepEqual on cyclic structures (e.g. doubly-linked LayoutNodes).
-	if fastEqual(s.value, val) {
+	// A custom equality from NewWithEquals takes precedence.
+	var equal bool
+	if s.equals != nil {
+		equal = s.equals(s.value, val)
+	} else {
+		equal = fastEqual(s.value, val)
+	}
+	// A computed recovering from a panic notifies even with the same value
+	if equal && s.panicked == nil {
 		s.mu.Unlock()
-		return
+		return nil
 	}
 
-	s.value = val
+	s.value, s.panicked = val, nil
+	if len(s.subscribers) == 0 {
+		s.mu.Unlock()
+		return nil
+	}
 	// Copy subscribers to avoid holding lock during notification
 	subs := make([]Subscriber, len(s.subscribers))
 	copy(subs, s.subscribers)
 	s.mu.Unlock()
+	return subs
+}
 
-	for _, sub := range subs {
-		sub.OnUpdate()
+// fail records that the computing effect panicked with p and notifies the
+// subscribers, whose reads then panic with p until the next Set. Only the
+// computing effect calls it, holding runMu.
+func (s *Signal[T]) fail(p interface{}) {
+	s.mu.Lock()
+	s.panicked = p
+	if len(s.subscribers) == 0 {
+		s.mu.Unlock()
+		return
+	}
+	subs := make([]Subscriber, len(s.subscribers))
+	copy(subs, s.subscribers)
+	s.mu.Unlock()
+
+	flush(subs)
+}
+
+// level is the signal's depth in the dependency graph: 0 for plain signals,
+// its computing effect's level for computed ones
+func (s *Signal[T]) level() int {
+	if s.owner == nil {
+		return 0
 	}
+	return s.owner.depth()
 }
 
 // fastEqual compares two values using interface == (pointer/value equality).
@@ -89,6 +165,25 @@ func fastEqual[T any](a, b T) bool {
 	return any(a) == any(b)
 }
 
+func (s *Signal[T]) unsubscribe(sub Subscriber) {
+	s.mu.Lock()
+	removed := false
+	for i, existing := range s.subscribers {
+		if existing == sub {
+			s.subscribers = append(s.subscribers[:i], s.subscribers[i+1:]...)
+			removed = true
+			break
+		}
+	}
+	unobserved := removed && len(s.subscribers) == 0
+	s.mu.Unlock()
+
+	// A computed nobody reads any more lets go of its own inputs
+	if unobserved && s.owner != nil {
+		s.owner.sleep()
+	}
+}
+
 func (s *Signal[T]) subscribe(sub Subscriber) {
 	s.mu.Lock() // Upgrade to Write Lock
 	defer s.mu.Unlock()
@@ -104,63 +199,578 @@ func (s *Signal[T]) subscribe(sub Subscriber) {
 
 // Effect represents a side effect that runs when signals change
 type Effect struct {
-	fn func()
+	fn       func()
+	schedule func(run func()) // Optional: decides when a re-run happens
+	priority int              // Higher runs first among queued effects, see updateQueue
+	computes bool             // Feeds a Computed's signal
+	root     bool             // Ownership scope from CreateRoot, has no fn
+
+	mu       sync.Mutex
+	level    int       // Depth in the dependency graph, see updateQueue
+	sources  []source  // Signals this effect subscribed to
+	owned    []*Effect // Effects and computeds created by the last run, see Dispose
+	disposed bool
+	cold     bool // A computed's effect unsubscribed until read again, see sleep
+}
+
+// source is a signal an effect can unsubscribe from
+type source interface {
+	unsubscribe(sub Subscriber)
+}
+
+// addSource records that e read src, a signal at the given level
+func (e *Effect) addSource(src source, level int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	// An effect sits one level above everything it reads
+	if level+1 > e.level {
+		e.level = level + 1
+	}
+	for _, existing := range e.sources {
+		if existing == src {
+			return
+		}
+	}
+	e.sources = append(e.sources, src)
+}
+
+// depth returns e's level in the dependency graph
+func (e *Effect) depth() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.level
+}
+
+// adopt makes child disposed along with e, or when e runs again
+func (e *Effect) adopt(child *Effect) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.owned = append(e.owned, child)
+}
+
+// Dispose unsubscribes the effect from every signal it read, so it never runs
+// again, and disposes the effects and computeds created inside it
+func (e *Effect) Dispose() {
+	e.mu.Lock()
+	if e.disposed {
+		e.mu.Unlock()
+		return
+	}
+	e.disposed = true
+	e.releaseLocked()
+}
+
+// sleep unsubscribes a computed's effect from its inputs once the last reader
+// of the computed has gone, so an unused computed doesn't keep its inputs
+// busy. The computed runs again, re-subscribing, when an effect next reads it.
+func (e *Effect) sleep() {
+	e.mu.Lock()
+	if e.disposed || e.cold {
+		e.mu.Unlock()
+		return
+	}
+	e.cold = true
+	e.releaseLocked()
+}
+
+// wake brings a computed's effect that went to sleep up to date before it is
+// read. For a tracking read it runs again, subscribing to its inputs; any
+// other read computes the value without subscribing, so the computed stays
+// asleep and its inputs stay free.
+func (e *Effect) wake(track bool) {
+	if !e.asleep() {
+		return
+	}
+	if !enter() {
+		defer leave()
+	}
+	if !e.asleep() {
+		return
+	}
+	if track {
+		e.run()
+		return
+	}
+	prevEffect := setCurrentEffect(nil)
+	defer setCurrentEffect(prevEffect)
+	callUser(e.fn)
+}
+
+// asleep reports whether e is a computed's effect that went to sleep
+func (e *Effect) asleep() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.cold && !e.disposed
+}
+
+// releaseLocked drops e's subscriptions and disposes what it created. It is
+// called with e.mu held and unlocks it.
+func (e *Effect) releaseLocked() {
+	sources, owned := e.sources, e.owned
+	e.sources, e.owned = nil, nil
+	e.mu.Unlock()
+
+	for _, src := range sources {
+		src.unsubscribe(e)
+	}
+	for _, child := range owned {
+		child.Dispose()
+	}
 }
 
 // OnUpdate implements the Subscriber interface
 func (e *Effect) OnUpdate() {
-	e.Run()
+	if !enter() {
+		defer leave()
+	}
+	e.update()
 }
 
-// Run executes the effect function while tracking dependencies
+// update re-runs e after a change, or hands the re-run to its schedule
+func (e *Effect) update() {
+	e.mu.Lock()
+	disposed := e.disposed
+	e.mu.Unlock()
+	if disposed {
+		return
+	}
+	if e.schedule != nil {
+		callUser(func() { e.schedule(e.Run) })
+		return
+	}
+	e.run()
+}
+
+// Run executes the effect function while tracking dependencies. Effects and
+// computeds created by the previous run are disposed first: a view function
+// that builds them on every render doesn't pile up subscriptions.
 func (e *Effect) Run() {
-	// Note: This global variable approach is not goroutine-safe.
-	// Effects should ideally be run on a single UI thread.
-	prevEffect := activeEffect
-	activeEffect = e
-	defer func() { activeEffect = prevEffect }()
+	if !enter() {
+		defer leave()
+	}
+	e.run()
+}
+
+// run is Run for a caller already holding runMu
+func (e *Effect) run() {
+	e.mu.Lock()
+	if e.disposed {
+		e.mu.Unlock()
+		return
+	}
+	e.cold = false
+	owned := e.owned
+	e.owned = nil
+	e.mu.Unlock()
+	for _, child := range owned {
+		child.Dispose()
+	}
+
+	prevEffect := setCurrentEffect(e)
+	defer setCurrentEffect(prevEffect)
+
+	callUser(e.fn)
+}
+
+// Effects and computeds run one goroutine at a time. A goroutine that sets a
+// signal with subscribers, creates an effect or starts a batch holds runMu
+// until the effects it started have run, and another goroutine doing the same
+// meanwhile waits for it. Whatever a running effect does in turn, setting
+// signals or creating effects, happens under the lock it already holds. An
+// effect must therefore not wait for a Set on another goroutine: that Set is
+// waiting for the effect.
+var (
+	runMu   sync.Mutex
+	current atomic.Value // The *Effect running under runMu, see currentEffect
+	queue   *updateQueue // The flush in progress under runMu, nil when none
+	spare   *updateQueue // The last flush's queue, emptied for the next one
+)
+
+// enter takes runMu for the calling goroutine. It reports whether the caller
+// is already running effects under it, in which case it must not call leave.
+func enter() (nested bool) {
+	if runMu.TryLock() {
+		return false
+	}
+	// Held: by this goroutine if it is inside code called by the package
+	if inUserCode() {
+		return true
+	}
+	runMu.Lock()
+	return false
+}
+
+// leave releases runMu after enter
+func leave() {
+	runMu.Unlock()
+}
 
-	e.fn()
+// callUser calls fn, an effect or any other function passed in from outside
+// the package, while runMu is held. Its frame is how enter knows that a call
+// from inside fn already holds the lock.
+//
+//go:noinline
+func callUser(fn func()) {
+	fn()
 }
 
-var activeEffect *Effect
+// userReturn is the return address of the call to fn in callUser
+var userReturn = func() uintptr {
+	var pc [1]uintptr
+	callUser(func() { runtime.Callers(2, pc[:]) })
+	return pc[0]
+}()
+
+// inUserCode reports whether callUser is on the calling goroutine's stack
+func inUserCode() bool {
+	var pcs [32]uintptr
+	for skip := 2; ; skip += len(pcs) {
+		n := runtime.Callers(skip, pcs[:])
+		for _, pc := range pcs[:n] {
+			if pc == userReturn {
+				return true
+			}
+		}
+		if n < len(pcs) {
+			return false
+		}
+	}
+}
+
+// currentEffect returns the effect running under runMu, or nil
+func currentEffect() *Effect {
+	e, _ := current.Load().(*Effect)
+	return e
+}
+
+// setCurrentEffect makes e the running effect (nil clears it) and returns the
+// previous one. Only the goroutine holding runMu calls it.
+func setCurrentEffect(e *Effect) *Effect {
+	prev := currentEffect()
+	current.Store(e)
+	return prev
+}
+
+// Update queue. A Set marks the affected effects dirty and then flushes them:
+// computeds lowest level first, then the other effects, highest priority
+// first. An effect runs only after every computed it reads has been brought
+// up to date, and runs once even if several of its inputs changed (no
+// "glitches" through diamond dependencies).
+//
+// A Set made from inside an effect brings the computeds it affects up to date
+// before it returns and leaves the effects to the flush already running.
+
+// updateQueue holds the effects waiting to run in a flush
+type updateQueue struct {
+	effects []*Effect
+	queued  map[*Effect]bool
+}
+
+// openQueue starts a flush, reusing the last one's queue
+func openQueue() *updateQueue {
+	q := spare
+	if q == nil {
+		q = &updateQueue{queued: map[*Effect]bool{}}
+	}
+	spare, queue = nil, q
+	return q
+}
+
+// closeQueue ends the flush of q, dropping anything still in it if an effect
+// panicked
+func closeQueue(q *updateQueue) {
+	for i, e := range q.effects {
+		delete(q.queued, e)
+		q.effects[i] = nil
+	}
+	q.effects = q.effects[:0]
+	spare, queue = q, nil
+}
+
+func (q *updateQueue) add(e *Effect) {
+	if !q.queued[e] {
+		q.queued[e] = true
+		q.effects = append(q.effects, e)
+	}
+}
+
+// next removes and returns the effect to run next, or nil if there is none.
+// With computedsOnly, other effects are left waiting.
+func (q *updateQueue) next(computedsOnly bool) *Effect {
+	pick := -1
+	for i, e := range q.effects {
+		if computedsOnly && !e.computes {
+			continue
+		}
+		// Ties keep queue order
+		if pick < 0 || e.runsBefore(q.effects[pick]) {
+			pick = i
+		}
+	}
+	if pick < 0 {
+		return nil
+	}
+	e := q.effects[pick]
+	q.effects = append(q.effects[:pick], q.effects[pick+1:]...)
+	delete(q.queued, e)
+	return e
+}
+
+// run runs queued effects, see next, until there are none left
+func (q *updateQueue) run(computedsOnly bool) {
+	for e := q.next(computedsOnly); e != nil; e = q.next(computedsOnly) {
+		e.update()
+	}
+}
+
+// notify queues the effects among subs and flushes the queue. Other
+// Subscriber implementations are called right away.
+func notify(subs []Subscriber) {
+	if !enter() {
+		defer leave()
+	}
+	flush(subs)
+}
+
+// flush is notify for a caller already holding runMu
+func flush(subs []Subscriber) {
+	q, outer := queue, queue == nil
+	if outer {
+		q = openQueue()
+		defer closeQueue(q)
+	}
+	for _, sub := range subs {
+		if e, ok := sub.(*Effect); ok {
+			q.add(e)
+		} else {
+			callUser(sub.OnUpdate)
+		}
+	}
+
+	if outer {
+		q.run(false)
+		return
+	}
+	// Inside a flush. A computed recomputing leaves its dependents to the
+	// level-ordered loop already running; any other Set brings the computeds
+	// downstream of it up to date now, so reads after it see the new value.
+	if cur := currentEffect(); cur == nil || !cur.computes {
+		q.run(true)
+	}
+}
+
+// Batch runs fn, holding back the effects of the Sets inside it until fn
+// returns, so an effect reading several of the signals it sets runs once and
+// never sees some updated and others not. Computeds still catch up as each Set
+// happens. Inside an effect, the effects wait for the flush already running.
+func Batch(fn func()) {
+	if !enter() {
+		defer leave()
+	}
+	if queue != nil {
+		callUser(fn)
+		return
+	}
+	q := openQueue()
+	defer closeQueue(q)
+	callUser(fn)
+	q.run(false)
+}
+
+// runsBefore reports whether queued e runs ahead of o: computeds come first,
+// so effects never see a stale value, then higher priority, then lower level
+func (e *Effect) runsBefore(o *Effect) bool {
+	if e.computes != o.computes {
+		return e.computes
+	}
+	if !e.computes && e.priority != o.priority {
+		return e.priority > o.priority
+	}
+	return e.depth() < o.depth()
+}
 
 // CreateEffect creates and runs a new effect
 func CreateEffect(fn func()) *Effect {
-	e := &Effect{fn: fn}
-	e.Run()
+	return start(&Effect{fn: fn})
+}
+
+// CreateEffectWithPriority creates and runs an effect that, when a change
+// affects several effects, runs before those with a lower priority (0 for
+// CreateEffect) whatever order they were created in. tui.Render draws at a
+// negative priority, so effects syncing state for the view run first.
+func CreateEffectWithPriority(fn func(), priority int) *Effect {
+	return start(&Effect{fn: fn, priority: priority})
+}
+
+// CreateScheduledEffect creates an effect that runs once immediately, but on
+// later updates hands its re-run to schedule instead of running synchronously.
+// schedule may defer, coalesce or drop re-runs (e.g. to cap a frame rate).
+func CreateScheduledEffect(fn func(), schedule func(run func())) *Effect {
+	return start(&Effect{fn: fn, schedule: schedule})
+}
+
+// start hands e to the effect or root running on this goroutine, if any,
+// which disposes it along with itself, and runs it for the first time
+func start(e *Effect) *Effect {
+	if !enter() {
+		defer leave()
+	}
+	if parent := currentEffect(); parent != nil {
+		parent.adopt(e)
+	}
+	e.run()
 	return e
 }
 
+// CreateRoot runs fn in a new ownership scope. Effects and computeds created
+// inside fn (and inside those) belong to the root rather than to any effect
+// running outside it, and are all disposed when fn's dispose is called. Reads
+// in fn itself are not tracked.
+//
+//	signals.CreateRoot(func(dispose func()) {
+//		signals.CreateEffect(func() { fmt.Println(count.Get()) })
+//		stop = dispose
+//	})
+func CreateRoot(fn func(dispose func())) {
+	if !enter() {
+		defer leave()
+	}
+	root := &Effect{root: true}
+	prevEffect := setCurrentEffect(root)
+	defer setCurrentEffect(prevEffect)
+	callUser(func() { fn(root.Dispose) })
+}
+
 // Computed represents a value derived from other signals
 type Computed[T any] struct {
 	sig *Signal[T]
 	fn  func() T
 }
 
-// NewComputed creates a new Computed value
+// NewComputed creates a new Computed value.
+// Subscribers are only notified when a recomputation yields a different value.
+// If fn panics, reading the computed panics the same way until fn next
+// succeeds: the panic reaches the effect reading it, not the Set that made it
+// recompute.
 func NewComputed[T any](fn func() T) *Computed[T] {
+	var zero T
+	return newComputed(fn, New(zero))
+}
+
+// NewComputedWithEquals is NewComputed with a custom equality deciding whether
+// a recomputed value is new, as with NewWithEquals.
+func NewComputedWithEquals[T any](fn func() T, eq func(a, b T) bool) *Computed[T] {
+	var zero T
+	return newComputed(fn, NewWithEquals(zero, eq))
+}
+
+// newComputed wires fn to write its result into sig
+func newComputed[T any](fn func() T, sig *Signal[T]) *Computed[T] {
 	c := &Computed[T]{
 		fn: fn,
 	}
-	// Create an internal signal to hold the result
-	var zero T
-	c.sig = New(zero)
+	// An internal signal holds the result; its equality check keeps an
+	// unchanged recomputation from reaching subscribers
+	c.sig = sig
 
-	// Create an effect that updates the internal signal whenever dependencies change
-	CreateEffect(func() {
-		c.sig.Set(c.fn())
-	})
+	// Create an effect that updates the internal signal whenever dependencies
+	// change. The first value is stored as is: comparing it with the zero
+	// value the signal starts with could keep a different value out.
+	first := true
+	e := &Effect{fn: func() {
+		v, p := c.compute()
+		if p != nil {
+			first = false
+			c.sig.fail(p)
+			return
+		}
+		if first {
+			first = false
+			c.sig.mu.Lock()
+			c.sig.value = v
+			c.sig.mu.Unlock()
+			return
+		}
+		if subs := c.sig.swap(v); subs != nil {
+			flush(subs)
+		}
+	}, computes: true}
+	c.sig.owner = e
+	// A computed created inside an effect lives until that effect re-runs
+	start(e)
 
 	return c
 }
 
+// compute runs fn, recovering a panic in it
+func (c *Computed[T]) compute() (v T, panicked interface{}) {
+	defer func() { panicked = recover() }()
+	return c.fn(), nil
+}
+
+// Dispose unsubscribes the computed from its dependencies. It stops updating
+// and Get returns the last value. Computeds created inside an effect are
+// disposed automatically when that effect runs again.
+func (c *Computed[T]) Dispose() {
+	c.sig.owner.Dispose()
+}
+
 // Get returns the computed value (and tracks dependency on the internal signal)
 func (c *Computed[T]) Get() T {
 	return c.sig.Get()
 }
 
+// Peek returns the computed value without tracking dependency. Computeds
+// recompute as soon as their inputs change, so the value is always current.
+func (c *Computed[T]) Peek() T {
+	return c.sig.Peek()
+}
+
 // GetValue implements the Getter interface for Computed
 func (c *Computed[T]) GetValue() interface{} {
 	return c.Get()
 }
+
+// Map derives a Computed from a single signal: Map(count, func(n int) int { return n * 2 }).
+// The result depends on src only; signals read inside fn are not tracked.
+func Map[T, R any](src *Signal[T], fn func(T) R) *Computed[R] {
+	return NewComputed(func() R {
+		v := src.Get()
+		return untracked(func() R { return fn(v) })
+	})
+}
+
+// untracked runs fn without registering dependencies on the active effect
+func untracked[R any](fn func() R) R {
+	prevEffect := setCurrentEffect(nil)
+	defer setCurrentEffect(prevEffect)
+	return fn()
+}
+
+// Combine2 derives a Computed from two signals. It depends on a and b only.
+func Combine2[A, B, R any](a *Signal[A], b *Signal[B], fn func(A, B) R) *Computed[R] {
+	return NewComputed(func() R {
+		va, vb := a.Get(), b.Get()
+		return untracked(func() R { return fn(va, vb) })
+	})
+}
+
+// Combine3 derives a Computed from three signals. It depends on a, b and c only.
+func Combine3[A, B, C, R any](a *Signal[A], b *Signal[B], c *Signal[C], fn func(A, B, C) R) *Computed[R] {
+	return NewComputed(func() R {
+		va, vb, vc := a.Get(), b.Get(), c.Get()
+		return untracked(func() R { return fn(va, vb, vc) })
+	})
+}
+
+// CombineAll derives a Computed from any number of signals of the same type.
+// fn receives their values in order.
+func CombineAll[T, R any](fn func([]T) R, sigs ...*Signal[T]) *Computed[R] {
+	return NewComputed(func() R {
+		vals := make([]T, len(sigs))
+		for i, s := range sigs {
+			vals[i] = s.Get()
+		}
+		return untracked(func() R { return fn(vals) })
+	})
+}
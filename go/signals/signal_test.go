@@ -1,6 +1,8 @@
 package signals
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 )
 
@@ -16,6 +18,31 @@ func TestSignal(t *testing.T) {
 	}
 }
 
+func TestNewWithEquals(t *testing.T) {
+	type item struct {
+		ID   int
+		Tags []string // Makes the struct non-comparable with ==
+	}
+	sameID := func(a, b item) bool { return a.ID == b.ID }
+	sig := NewWithEquals(item{ID: 1}, sameID)
+
+	runs := 0
+	CreateEffect(func() {
+		sig.Get()
+		runs++
+	})
+
+	sig.Set(item{ID: 1, Tags: []string{"new"}})
+	if runs != 1 {
+		t.Errorf("Expected an equal value not to notify, got %d runs", runs)
+	}
+
+	sig.Set(item{ID: 2})
+	if runs != 2 {
+		t.Errorf("Expected a different value to notify, got %d runs", runs)
+	}
+}
+
 func TestEffect(t *testing.T) {
 	count := New(0)
 	runCount := 0
@@ -79,3 +106,556 @@ func TestDependencyTracking(t *testing.T) {
 		t.Errorf("Expected 5, got %d", sum)
 	}
 }
+
+func TestConcurrentComputedsTrackOwnDependencies(t *testing.T) {
+	const rounds = 200
+	for i := 0; i < rounds; i++ {
+		a, b := New(1), New(2)
+		var ca, cb *Computed[int]
+
+		// Both goroutines build their computed at once, each reading only its own input
+		var wg sync.WaitGroup
+		wg.Add(2)
+		ready := make(chan struct{})
+		build := func(src *Signal[int], dst **Computed[int]) {
+			defer wg.Done()
+			<-ready
+			*dst = NewComputed(func() int { return src.Get() * 10 })
+		}
+		go build(a, &ca)
+		go build(b, &cb)
+		close(ready)
+		wg.Wait()
+
+		if len(a.subscribers) != 1 || a.subscribers[0] != ca.sig.owner ||
+			len(b.subscribers) != 1 || b.subscribers[0] != cb.sig.owner {
+			t.Fatalf("Round %d: expected each signal subscribed by its own computed only, got %v and %v",
+				i, a.subscribers, b.subscribers)
+		}
+		if ca.Get() != 10 || cb.Get() != 20 {
+			t.Fatalf("Round %d: expected 10 and 20, got %d and %d", i, ca.Get(), cb.Get())
+		}
+	}
+}
+
+func TestComputedPeekDoesNotTrack(t *testing.T) {
+	count := New(1)
+	double := NewComputed(func() int { return count.Get() * 2 })
+
+	runs := 0
+	var seen int
+	CreateEffect(func() {
+		seen = double.Peek()
+		runs++
+	})
+	if seen != 2 {
+		t.Errorf("Expected Peek to return 2, got %d", seen)
+	}
+
+	count.Set(5)
+	if runs != 1 {
+		t.Errorf("Expected Peek not to subscribe the effect, got %d runs", runs)
+	}
+	if double.Peek() != 10 {
+		t.Errorf("Expected Peek to see the updated value 10, got %d", double.Peek())
+	}
+}
+
+func TestComputedDispose(t *testing.T) {
+	count := New(1)
+	double := NewComputed(func() int { return count.Get() * 2 })
+	triple := NewComputed(func() int { return count.Get() * 3 })
+	if len(count.subscribers) != 2 {
+		t.Fatalf("Expected 2 subscribers, got %d", len(count.subscribers))
+	}
+
+	double.Dispose()
+	if len(count.subscribers) != 1 || count.subscribers[0] != triple.sig.owner {
+		t.Errorf("Expected only the live computed subscribed, got %v", count.subscribers)
+	}
+	count.Set(5)
+	if double.Get() != 2 || triple.Get() != 15 {
+		t.Errorf("Expected the disposed computed frozen at 2 and the other at 15, got %d and %d", double.Get(), triple.Get())
+	}
+	double.Dispose() // Twice is fine
+}
+
+func TestUnreadComputedReleasesInputs(t *testing.T) {
+	count := New(1)
+	double := NewComputed(func() int { return count.Get() * 2 })
+	label := Map(double.sig, func(n int) string { return fmt.Sprint(n) })
+	var seen []string
+	first := CreateEffect(func() { seen = append(seen, label.Get()) })
+	second := CreateEffect(func() { double.Get() })
+
+	first.Dispose()
+	if len(count.subscribers) != 1 {
+		t.Fatalf("Expected double kept live by the second effect, got %d subscribers", len(count.subscribers))
+	}
+	second.Dispose()
+	if len(count.subscribers) != 0 || len(double.sig.subscribers) != 0 {
+		t.Fatalf("Expected the chain released once nothing reads it, got %d and %d subscribers", len(count.subscribers), len(double.sig.subscribers))
+	}
+
+	// Reading again subscribes again, with a fresh value
+	count.Set(5)
+	if got := label.Peek(); got != "10" {
+		t.Errorf("Expected the woken computed recomputed, got %q", got)
+	}
+	CreateEffect(func() { seen = append(seen, label.Get()) })
+	count.Set(6)
+	if len(count.subscribers) != 1 || fmt.Sprint(seen) != "[2 10 12]" {
+		t.Errorf("Expected the chain live again, got %d subscribers and %v", len(count.subscribers), seen)
+	}
+}
+
+func TestPeekLeavesUnreadComputedAsleep(t *testing.T) {
+	count := New(1)
+	double := NewComputed(func() int { return count.Get() * 2 })
+	label := Map(double.sig, func(n int) string { return fmt.Sprint(n) })
+	CreateEffect(func() { label.Get() }).Dispose()
+	if len(count.subscribers) != 0 {
+		t.Fatalf("Expected the chain asleep, got %d subscribers", len(count.subscribers))
+	}
+
+	count.Set(5)
+	if got := label.Peek(); got != "10" {
+		t.Errorf("Expected Peek to compute the current value, got %q", got)
+	}
+	if got := double.Get(); got != 10 {
+		t.Errorf("Expected Get outside an effect to compute the current value, got %d", got)
+	}
+	if len(count.subscribers) != 0 || len(double.sig.subscribers) != 0 {
+		t.Errorf("Expected reads outside effects to leave the chain asleep, got %d and %d subscribers",
+			len(count.subscribers), len(double.sig.subscribers))
+	}
+}
+
+func TestCreateRootDisposesComputeds(t *testing.T) {
+	a, b := New(1), New(2)
+	var sum *Computed[int]
+	CreateRoot(func(dispose func()) {
+		sum = NewComputed(func() int { return a.Get() + b.Get() })
+		dispose()
+	})
+	if len(a.subscribers) != 0 || len(b.subscribers) != 0 {
+		t.Errorf("Expected the computed gone from both inputs, got %d and %d subscribers", len(a.subscribers), len(b.subscribers))
+	}
+	a.Set(10)
+	if sum.Get() != 3 {
+		t.Errorf("Expected the disposed computed to keep its last value, got %d", sum.Get())
+	}
+}
+
+func TestEffectDisposesComputedsItCreated(t *testing.T) {
+	count := New(1)
+	trigger := New(0)
+	var latest *Computed[int]
+	CreateEffect(func() {
+		trigger.Get()
+		// A view building a fresh computed on every run
+		latest = NewComputed(func() int { return count.Get() + 1 })
+	})
+
+	for i := 1; i <= 5; i++ {
+		trigger.Set(i)
+	}
+	if len(count.subscribers) != 1 || count.subscribers[0] != latest.sig.owner {
+		t.Errorf("Expected only the latest computed subscribed, got %d subscribers", len(count.subscribers))
+	}
+	count.Set(10)
+	if latest.Get() != 11 {
+		t.Errorf("Expected the latest computed to stay live, got %d", latest.Get())
+	}
+}
+
+func TestCreateRootDisposesChildren(t *testing.T) {
+	count := New(1)
+	var runs, nested int
+	var double *Computed[int]
+	var dispose func()
+	CreateRoot(func(d func()) {
+		dispose = d
+		double = NewComputed(func() int { return count.Get() * 2 })
+		CreateEffect(func() {
+			runs++
+			count.Get()
+			CreateEffect(func() {
+				nested++
+				double.Get()
+			})
+		})
+	})
+
+	count.Set(2)
+	if runs != 2 || double.Peek() != 4 {
+		t.Fatalf("Expected the root's effects to react, got %d runs, double %d", runs, double.Peek())
+	}
+	dispose()
+	runs, nested = 0, 0
+	count.Set(3)
+	if runs != 0 || nested != 0 || double.Peek() != 4 {
+		t.Errorf("Expected nothing to react after dispose, got %d runs, %d nested, double %d", runs, nested, double.Peek())
+	}
+	if len(count.subscribers) != 0 {
+		t.Errorf("Expected no subscribers left, got %d", len(count.subscribers))
+	}
+}
+
+func TestEffectPriority(t *testing.T) {
+	count := New(1)
+	double := NewComputed(func() int { return count.Get() * 2 })
+	var order []string
+	CreateEffectWithPriority(func() {
+		order = append(order, fmt.Sprintf("low %d", count.Get()))
+	}, -1)
+	CreateEffect(func() {
+		order = append(order, fmt.Sprintf("default %d", count.Get()))
+	})
+	CreateEffectWithPriority(func() {
+		// Computeds are still brought up to date first
+		order = append(order, fmt.Sprintf("high %d", double.Get()))
+	}, 1)
+
+	order = nil
+	count.Set(2)
+	want := []string{"high 4", "default 2", "low 2"}
+	if fmt.Sprint(order) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, order)
+	}
+}
+
+func TestDiamondIsGlitchFree(t *testing.T) {
+	a := New(1)
+	double := NewComputed(func() int { return a.Get() * 2 })
+	triple := NewComputed(func() int { return a.Get() * 3 })
+
+	var seen []string
+	CreateEffect(func() {
+		seen = append(seen, fmt.Sprintf("%d+%d", double.Get(), triple.Get()))
+	})
+
+	a.Set(2)
+	// One run for the initial value, one for the update, never a mix of both
+	if len(seen) != 2 || seen[1] != "4+6" {
+		t.Errorf("Expected [2+3 4+6], got %v", seen)
+	}
+}
+
+func TestEffectRunsOncePerSet(t *testing.T) {
+	a := New(1)
+	// Two paths of different depth from a to the effect
+	short := NewComputed(func() int { return a.Get() + 1 })
+	mid := NewComputed(func() int { return a.Get() * 10 })
+	long := NewComputed(func() int { return mid.Get() + 1 })
+
+	runs := 0
+	var seen []int
+	CreateEffect(func() {
+		runs++
+		seen = append(seen, short.Get()+long.Get())
+	})
+
+	for i := 2; i <= 4; i++ {
+		a.Set(i)
+	}
+	if runs != 4 {
+		t.Errorf("Expected one run per Set plus the first, got %d", runs)
+	}
+	if fmt.Sprint(seen) != "[13 24 35 46]" {
+		t.Errorf("Expected only consistent values, got %v", seen)
+	}
+}
+
+func TestNestedSetUpdatesComputeds(t *testing.T) {
+	a := New(1)
+	double := NewComputed(func() int { return a.Get() * 2 })
+	trigger := New(0)
+	var seen []int
+	CreateEffect(func() {
+		if trigger.Get() == 0 {
+			return
+		}
+		// The computed catches up before Set returns, mid-flush
+		a.Set(5)
+		seen = append(seen, double.Peek())
+	})
+	var later []int
+	CreateEffect(func() { later = append(later, double.Get()) })
+
+	trigger.Set(1)
+	if fmt.Sprint(seen) != "[10]" {
+		t.Errorf("Expected the effect to read the updated computed, got %v", seen)
+	}
+	if fmt.Sprint(later) != "[2 10]" {
+		t.Errorf("Expected the dependent effect to run once for the nested Set, got %v", later)
+	}
+}
+
+func TestSetFromAnotherGoroutineDuringFlush(t *testing.T) {
+	a, b := New(0), New(0)
+	double := NewComputed(func() int { return b.Get() * 2 })
+	var mu sync.Mutex
+	seen := 0
+	CreateEffect(func() {
+		v := b.Get()
+		mu.Lock()
+		seen = v
+		mu.Unlock()
+	})
+
+	started, setting := make(chan struct{}), make(chan struct{})
+	CreateEffect(func() {
+		if a.Get() == 0 {
+			return
+		}
+		// Keep this flush running until the other goroutine is about to set b
+		close(started)
+		<-setting
+	})
+
+	errs := make(chan string, 2)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-started
+		close(setting)
+		// Waits for the flush on the other goroutine, then runs its own
+		b.Set(7)
+		mu.Lock()
+		defer mu.Unlock()
+		if seen != 7 {
+			errs <- fmt.Sprintf("Expected b's effect to have run when Set returned, saw %d", seen)
+		}
+		if got := double.Peek(); got != 14 {
+			errs <- fmt.Sprintf("Expected the computed current when Set returned, got %d", got)
+		}
+	}()
+	a.Set(1)
+	<-done
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestComputedFirstValueSkipsEquality(t *testing.T) {
+	type user struct {
+		ID   int
+		Name string
+	}
+	sameID := func(a, b user) bool { return a.ID == b.ID }
+	c := NewComputedWithEquals(func() user { return user{ID: 0, Name: "bob"} }, sameID)
+	if got := c.Peek(); got.Name != "bob" {
+		t.Errorf("Expected the first value kept even though it equals the zero value, got %+v", got)
+	}
+}
+
+func TestComputedPanicReachesReader(t *testing.T) {
+	items := []string{"a", "b"}
+	idx := New(0)
+	item := NewComputed(func() string { return items[idx.Get()] })
+
+	var caught []interface{}
+	var seen string
+	CreateEffect(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				caught = append(caught, r)
+			}
+		}()
+		seen = ""
+		seen = item.Get()
+	})
+
+	// The Set itself returns normally; the reading effect sees the panic
+	idx.Set(5)
+	if len(caught) != 1 {
+		t.Fatalf("Expected the reader to catch one panic, got %v", caught)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected Peek to panic while the computed is failing")
+			}
+		}()
+		item.Peek()
+	}()
+
+	// Recovering to the old value still notifies the reader
+	idx.Set(0)
+	if seen != "a" {
+		t.Errorf("Expected the reader to re-run with %q, got %q", "a", seen)
+	}
+	if len(caught) != 1 {
+		t.Errorf("Expected no more panics, got %v", caught)
+	}
+}
+
+func TestBatchRunsEffectsOnce(t *testing.T) {
+	first, last := New("a"), New("b")
+	full := NewComputed(func() string { return first.Get() + " " + last.Get() })
+	var seen []string
+	CreateEffect(func() { seen = append(seen, full.Get()) })
+
+	Batch(func() {
+		first.Set("c")
+		if got := full.Peek(); got != "c b" {
+			t.Errorf("Expected the computed current inside the batch, got %q", got)
+		}
+		last.Set("d")
+	})
+	if fmt.Sprintf("%q", seen) != `["a b" "c d"]` {
+		t.Errorf("Expected one run for the batch, got %q", seen)
+	}
+}
+
+func TestComputedEqualValueDoesNotPropagate(t *testing.T) {
+	count := New(1)
+	parity := NewComputed(func() int { return count.Get() % 2 })
+
+	runs := 0
+	CreateEffect(func() {
+		parity.Get()
+		runs++
+	})
+
+	count.Set(3) // Parity is still 1
+	if runs != 1 {
+		t.Errorf("Expected an unchanged computed not to re-run the effect, got %d runs", runs)
+	}
+	count.Set(4)
+	if runs != 2 {
+		t.Errorf("Expected a changed computed to re-run the effect, got %d runs", runs)
+	}
+
+	// Custom equality for a type == can't compare
+	items := New([]string{"a"})
+	sameLen := func(a, b []string) bool { return len(a) == len(b) }
+	upper := NewComputedWithEquals(func() []string {
+		return append([]string(nil), items.Get()...)
+	}, sameLen)
+
+	runs = 0
+	CreateEffect(func() {
+		upper.Get()
+		runs++
+	})
+	items.Set([]string{"b"})
+	if runs != 1 {
+		t.Errorf("Expected the custom equality to stop propagation, got %d runs", runs)
+	}
+	items.Set([]string{"b", "c"})
+	if runs != 2 {
+		t.Errorf("Expected a different length to propagate, got %d runs", runs)
+	}
+}
+
+func TestMap(t *testing.T) {
+	count := New(1)
+	other := New(10)
+	calls := 0
+
+	double := Map(count, func(n int) int {
+		calls++
+		_ = other.Get() // Not tracked
+		return n * 2
+	})
+
+	if double.Get() != 2 {
+		t.Errorf("Expected 2, got %d", double.Get())
+	}
+
+	count.Set(5)
+	if double.Get() != 10 {
+		t.Errorf("Expected 10, got %d", double.Get())
+	}
+
+	// Reads are cached and unrelated signals don't trigger a recompute
+	before := calls
+	double.Get()
+	double.Get()
+	other.Set(20)
+	if calls != before {
+		t.Errorf("Expected no recompute, got %d extra calls", calls-before)
+	}
+}
+
+func TestCombine(t *testing.T) {
+	x := New(1)
+	y := New(2)
+	unrelated := New(0)
+	calls := 0
+
+	pos := Combine2(x, y, func(x, y int) string {
+		calls++
+		_ = unrelated.Get() // Not tracked
+		return fmt.Sprintf("%d,%d", x, y)
+	})
+
+	if pos.Get() != "1,2" {
+		t.Errorf("Expected 1,2, got %s", pos.Get())
+	}
+
+	x.Set(3)
+	if pos.Get() != "3,2" {
+		t.Errorf("Expected 3,2, got %s", pos.Get())
+	}
+	y.Set(4)
+	if pos.Get() != "3,4" {
+		t.Errorf("Expected 3,4, got %s", pos.Get())
+	}
+
+	before := calls
+	unrelated.Set(1)
+	if calls != before {
+		t.Errorf("Expected no recompute for an unrelated signal")
+	}
+
+	a, b, c := New(1), New(2), New(3)
+	sum := CombineAll(func(vals []int) int {
+		total := 0
+		for _, v := range vals {
+			total += v
+		}
+		return total
+	}, a, b, c)
+	c.Set(10)
+	if sum.Get() != 13 {
+		t.Errorf("Expected 13, got %d", sum.Get())
+	}
+
+	label := Combine3(a, b, New("!"), func(a, b int, s string) string {
+		return fmt.Sprintf("%d%d%s", a, b, s)
+	})
+	b.Set(5)
+	if label.Get() != "15!" {
+		t.Errorf("Expected 15!, got %s", label.Get())
+	}
+}
+
+func BenchmarkSetNoSubscribers(b *testing.B) {
+	count := New(0)
+	for i := 0; i < b.N; i++ {
+		count.Set(i)
+	}
+}
+
+func BenchmarkSetOneEffect(b *testing.B) {
+	count := New(0)
+	CreateEffect(func() { count.Get() })
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		count.Set(i + 1)
+	}
+}
+
+func BenchmarkSetThroughComputed(b *testing.B) {
+	count := New(0)
+	double := NewComputed(func() int { return count.Get() * 2 })
+	CreateEffect(func() { double.Get() })
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		count.Set(i + 1)
+	}
+}
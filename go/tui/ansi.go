@@ -0,0 +1,191 @@
+package tui
+
+import (
+	"basement/basement"
+	"strconv"
+	"strings"
+)
+
+// Raw is text that already holds ANSI escapes, e.g. the output of
+// basement.Parse. Passed to a template hole, its SGR escapes (\x1b[...m)
+// become cell styles instead of being drawn as text.
+type Raw string
+
+// ParseANSI splits s into runs of text, each with the style set by the SGR
+// escapes (\x1b[...m) before it, ready for DrawSpans. Attributes, resets and
+// 16, 256 and 24-bit colors are understood; unknown SGR codes are ignored and
+// other escape sequences dropped.
+func ParseANSI(s string) []Span {
+	var spans []Span
+	var style basement.Style
+	var text strings.Builder
+	emit := func() {
+		if text.Len() > 0 {
+			spans = append(spans, Span{Text: text.String(), Style: style})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		if s[i] != 0x1b {
+			j := strings.IndexByte(s[i:], 0x1b)
+			if j < 0 {
+				j = len(s) - i
+			}
+			text.WriteString(s[i : i+j])
+			i += j
+			continue
+		}
+		// CSI: ESC [ parameters, then a final byte in @-~
+		if i+1 >= len(s) || s[i+1] != '[' {
+			i += 2
+			continue
+		}
+		j := i + 2
+		for j < len(s) && (s[j] < '@' || s[j] > '~') {
+			j++
+		}
+		if j == len(s) {
+			break
+		}
+		if s[j] == 'm' {
+			emit()
+			style = applySGR(style, s[i+2:j])
+		}
+		i = j + 1
+	}
+	emit()
+	return spans
+}
+
+// applySGR returns style with the SGR parameters in params ("1;31") applied
+func applySGR(style basement.Style, params string) basement.Style {
+	if params == "" {
+		return basement.Style{}
+	}
+	ps := strings.Split(params, ";")
+	for i := 0; i < len(ps); i++ {
+		p := ps[i]
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			continue
+		}
+		switch {
+		case n == 0:
+			style = basement.Style{}
+		case n == 1:
+			style.Bold = true
+		case n == 2:
+			style.Dim = true
+		case n == 3:
+			style.Italic = true
+		case n == 4:
+			style.Underline = true
+		case n == 5:
+			style.Blink = true
+		case n == 7:
+			style.Reverse = true
+		case n == 8:
+			style.Hidden = true
+		case n == 9:
+			style.Strike = true
+		case n == 22:
+			style.Bold, style.Dim = false, false
+		case n == 23:
+			style.Italic = false
+		case n == 24:
+			style.Underline = false
+		case n == 25:
+			style.Blink = false
+		case n == 27:
+			style.Reverse = false
+		case n == 28:
+			style.Hidden = false
+		case n == 29:
+			style.Strike = false
+		case n >= 30 && n <= 37 || n >= 90 && n <= 97:
+			style.Color = "\x1b[" + p + "m"
+		case n == 38:
+			code, used := extendedColor(ps[i:])
+			if used == 0 {
+				// Malformed: the rest can't be told apart from its arguments
+				return style
+			}
+			style.Color = code
+			i += used - 1
+		case n == 39:
+			style.Color = ""
+		case n >= 40 && n <= 47 || n >= 100 && n <= 107:
+			style.BgColor = "\x1b[" + p + "m"
+		case n == 48:
+			code, used := extendedColor(ps[i:])
+			if used == 0 {
+				// Malformed: the rest can't be told apart from its arguments
+				return style
+			}
+			style.BgColor = code
+			i += used - 1
+		case n == 49:
+			style.BgColor = ""
+		}
+	}
+	return style
+}
+
+// extendedColor reads a 256-color (38;5;n) or 24-bit (38;2;r;g;b) color from
+// ps, which starts at the 38 or 48. It returns the color's escape code and how
+// many parameters it took, or 0 if they are malformed.
+func extendedColor(ps []string) (string, int) {
+	used := 0
+	switch {
+	case len(ps) >= 3 && ps[1] == "5":
+		used = 3
+	case len(ps) >= 5 && ps[1] == "2":
+		used = 5
+	default:
+		return "", 0
+	}
+	for _, p := range ps[2:used] {
+		if n, err := strconv.Atoi(p); err != nil || n < 0 || n > 255 {
+			return "", 0
+		}
+	}
+	return "\x1b[" + strings.Join(ps[:used], ";") + "m", used
+}
+
+// DrawSpans draws styled runs of text (from ParseANSI or Highlight) from
+// (x, y), following on from each other. A newline moves to the next row,
+// back at x.
+func (s *Screen) DrawSpans(x, y int, spans []Span) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drawSpansUnlocked(x, y, spans, basement.Style{})
+}
+
+// drawRawUnlocked draws raw text from (x, y) on top of style and returns
+// where the next node continues, like a plain text hole
+func (s *Screen) drawRawUnlocked(x, y int, raw Raw, style basement.Style) (int, int) {
+	return s.drawSpansUnlocked(x, y, ParseANSI(string(raw)), style)
+}
+
+// drawSpansUnlocked is DrawSpans on top of style, for use within Frame(). It
+// returns the position after the last span.
+func (s *Screen) drawSpansUnlocked(x, y int, spans []Span, style basement.Style) (int, int) {
+	curX, curY := x, y
+	for _, span := range spans {
+		text := span.Text
+		st := mergeStyles(style, span.Style)
+		// Each line piece is expanded where it starts, mid-line or at x
+		for {
+			i := strings.IndexByte(text, '\n')
+			if i < 0 {
+				curX = s.Back.SetString(curX, curY, expandTabs(text, curX+s.originX), st)
+				break
+			}
+			s.Back.SetString(curX, curY, expandTabs(text[:i], curX+s.originX), st)
+			text = text[i+1:]
+			curX, curY = x, curY+1
+		}
+	}
+	return curX, curY
+}
@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"basement/basement"
+	"io"
+	"testing"
+)
+
+func TestRawHoleStylesCells(t *testing.T) {
+	s := renderHeadless(Template("= %v!", Raw("\x1b[1mbold\x1b[22m \x1b[31mred\x1b[0m")), 20, 1)
+	if got := s.Back.String(); got != "= bold red!\n" {
+		t.Fatalf("Expected the escapes interpreted, not drawn, got %q", got)
+	}
+	if st := s.Back.Get(2, 0).Style; !st.Bold {
+		t.Errorf("Expected bold cells, got %+v", st)
+	}
+	if st := s.Back.Get(7, 0).Style; st.Bold || st.Color != "\x1b[31m" {
+		t.Errorf("Expected red, not bold, cells, got %+v", st)
+	}
+	if st := s.Back.Get(10, 0).Style; st != (basement.Style{}) {
+		t.Errorf("Expected the text after the hole unstyled, got %+v", st)
+	}
+
+	// In a layout it is measured without its escapes
+	s = renderHeadless(Template("%v", Box(Raw("\x1b[7mab\x1b[0m"), true, 0)), 10, 3)
+	if got := s.Back.String(); got != "┌──┐\n│ab│\n└──┘\n" || !s.Back.Get(1, 1).Style.Reverse {
+		t.Errorf("Expected the raw text boxed, got %q", got)
+	}
+
+	// Raw text isn't markup, and its tabs count from the box's edge
+	s = renderHeadless(Template("%v", Box(Raw("\x1b[1m**a**\x1b[0m\nabcde\x1b[31m\tc"), true, 0)), 12, 4)
+	if got := s.Back.String(); got != "┌─────────┐\n│**a**    │\n│abcde   c│\n└─────────┘\n" {
+		t.Errorf("Expected the raw text measured as drawn, got %q", got)
+	}
+
+	// Output of the legacy parser drops straight in
+	s = renderHeadless(Template("%v", Raw(basement.Parse("**hi**"))), 10, 1)
+	if got := s.Back.String(); got != "hi\n" || !s.Back.Get(0, 0).Style.Bold {
+		t.Errorf("Expected Parse output drawn bold, got %q %+v", got, s.Back.Get(0, 0).Style)
+	}
+}
+
+func TestParseANSI(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []Span
+	}{
+		{"plain", []Span{{Text: "plain"}}},
+		// Nested: attributes add up, 22 turns bold off but keeps the color
+		{"\x1b[31ma\x1b[1mb\x1b[22mc\x1b[0md", []Span{
+			{Text: "a", Style: basement.Style{Color: "\x1b[31m"}},
+			{Text: "b", Style: basement.Style{Color: "\x1b[31m", Bold: true}},
+			{Text: "c", Style: basement.Style{Color: "\x1b[31m"}},
+			{Text: "d"},
+		}},
+		// Several codes in one escape, then an empty reset
+		{"\x1b[1;4;44mx\x1b[my", []Span{
+			{Text: "x", Style: basement.Style{Bold: true, Underline: true, BgColor: "\x1b[44m"}},
+			{Text: "y"},
+		}},
+		{"\x1b[38;5;208ma\x1b[48;2;10;20;30mb\x1b[39;49mc", []Span{
+			{Text: "a", Style: basement.Style{Color: "\x1b[38;5;208m"}},
+			{Text: "b", Style: basement.Style{Color: "\x1b[38;5;208m", BgColor: "\x1b[48;2;10;20;30m"}},
+			{Text: "c"},
+		}},
+		// Unknown codes, malformed colors and non-SGR escapes are skipped
+		{"\x1b[73;1ma\x1b[38;5mb\x1b[2Kc\x1b[", []Span{
+			{Text: "a", Style: basement.Style{Bold: true}},
+			{Text: "bc", Style: basement.Style{Bold: true}},
+		}},
+	}
+	for _, tt := range tests {
+		got := ParseANSI(tt.in)
+		if len(got) != len(tt.want) {
+			t.Errorf("%q: expected %+v, got %+v", tt.in, tt.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%q: span %d: expected %+v, got %+v", tt.in, i, tt.want[i], got[i])
+			}
+		}
+	}
+}
+
+func TestDrawSpans(t *testing.T) {
+	s := newHeadlessScreen(10, 2, io.Discard)
+	s.DrawSpans(1, 0, ParseANSI("ab\x1b[1mc\nd"))
+	if got := s.Back.String(); got != " abc\n d\n" {
+		t.Errorf("Expected spans drawn in sequence, got %q", got)
+	}
+	if !s.Back.Get(3, 0).Style.Bold || !s.Back.Get(1, 1).Style.Bold || s.Back.Get(2, 0).Style.Bold {
+		t.Errorf("Expected only the text after the escape bold")
+	}
+}
@@ -0,0 +1,117 @@
+package tui
+
+import "basement/signals"
+
+// Show places node in the layout only while cond (a signal or computed
+// holding a bool) is true, and an empty node otherwise. Reading cond during
+// the render makes a toggle redraw, without rebuilding or reparsing node.
+func Show(cond signals.Getter, node *LayoutNode) *LayoutNode {
+	return wrapChild(&showGetter{cond: cond, node: node, empty: Col()})
+}
+
+type showGetter struct {
+	cond  signals.Getter
+	node  *LayoutNode
+	empty *LayoutNode
+}
+
+// GetValue implements the Getter interface
+func (g *showGetter) GetValue() interface{} {
+	if on, _ := g.cond.GetValue().(bool); on {
+		return g.node
+	}
+	return g.empty
+}
+
+// For lays out one row per element of items (a signal or computed holding a
+// []T) in a column, building each row with render. Rows are keyed by element,
+// so the elements must be comparable values (see ForKey otherwise): after a
+// change only elements not in the last list get a new row, and prepending an
+// item builds just that row. index holds the row's current position, an int,
+// and follows the row as it moves.
+func For[T any](items signals.Getter, render func(item T, index signals.Getter) *LayoutNode) *LayoutNode {
+	return wrapChild(&forGetter[T]{items: items, key: func(item T) interface{} { return item }, render: render})
+}
+
+// ForKey is For with rows keyed by key(item) instead of the element itself,
+// for elements that aren't comparable or change while meaning the same row.
+func ForKey[T any, K comparable](items signals.Getter, key func(item T) K, render func(item T, index signals.Getter) *LayoutNode) *LayoutNode {
+	return wrapChild(&forGetter[T]{items: items, key: func(item T) interface{} { return key(item) }, render: render})
+}
+
+// forKey tells apart rows whose elements share a key by their occurrence
+type forKey struct {
+	key interface{}
+	n   int
+}
+
+type forRow struct {
+	node  *LayoutNode
+	index *forIndex
+}
+
+// forIndex is a row's position, updated in place when the row moves
+type forIndex struct {
+	i int
+}
+
+// GetValue implements the Getter interface
+func (x *forIndex) GetValue() interface{} {
+	return x.i
+}
+
+type forGetter[T any] struct {
+	items  signals.Getter
+	key    func(T) interface{}
+	render func(T, signals.Getter) *LayoutNode
+	rows   map[forKey]*forRow // Rows built for the last list
+	last   []interface{}      // Keys of the last list and its column,
+	view   *LayoutNode        // returned as is while the keys are the same
+}
+
+// GetValue implements the Getter interface
+func (g *forGetter[T]) GetValue() interface{} {
+	items, _ := g.items.GetValue().([]T)
+	keys := make([]interface{}, len(items))
+	for i, item := range items {
+		keys[i] = g.key(item)
+	}
+	if g.view != nil && sameKeys(keys, g.last) {
+		// Measure and Draw both resolve the node: keep handing out the one
+		// that was measured
+		return g.view
+	}
+
+	rows := make(map[forKey]*forRow, len(items))
+	seen := make(map[interface{}]int, len(items))
+	children := make([]interface{}, len(items))
+	for i, item := range items {
+		k := forKey{keys[i], seen[keys[i]]}
+		seen[keys[i]]++
+		row, ok := g.rows[k]
+		if ok {
+			row.index.i = i
+		} else {
+			row = &forRow{index: &forIndex{i}}
+			row.node = g.render(item, row.index)
+		}
+		rows[k] = row
+		children[i] = row.node
+	}
+	g.rows = rows
+	g.last = keys
+	g.view = Col(children...)
+	return g.view
+}
+
+func sameKeys(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
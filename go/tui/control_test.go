@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"basement/signals"
+	"testing"
+)
+
+func TestShowTogglesNode(t *testing.T) {
+	visible := signals.New(true)
+	view := Col("top", Show(visible, Text("**shown**")), "bottom")
+
+	if got := RenderToString(Template("%v", view), 10, 0); got != "top\nshown\nbottom\n" {
+		t.Errorf("Expected the node shown, got %q", got)
+	}
+	visible.Set(false)
+	if got := RenderToString(Template("%v", view), 10, 0); got != "top\nbottom\n" {
+		t.Errorf("Expected the node hidden, got %q", got)
+	}
+	visible.Set(true)
+	if got := RenderToString(Template("%v", view), 10, 0); got != "top\nshown\nbottom\n" {
+		t.Errorf("Expected the node shown again, got %q", got)
+	}
+}
+
+func TestForBuildsOnlyNewRows(t *testing.T) {
+	items := signals.New([]string{"a", "b"})
+	var built []string
+	view := For(items, func(item string, index signals.Getter) *LayoutNode {
+		built = append(built, item)
+		return Row(index, ": "+item)
+	})
+
+	if got := RenderToString(Template("%v", view), 10, 0); got != "0: a\n1: b\n" {
+		t.Errorf("Expected one row per item, got %q", got)
+	}
+
+	built = nil
+	items.Set([]string{"a", "b", "c"})
+	if got := RenderToString(Template("%v", view), 10, 0); got != "0: a\n1: b\n2: c\n" {
+		t.Errorf("Expected the new item appended, got %q", got)
+	}
+	if len(built) != 1 || built[0] != "c" {
+		t.Errorf("Expected only the new row built, got %v", built)
+	}
+
+	// Prepending builds the new row only; the others move down
+	built = nil
+	items.Set([]string{"z", "a", "b", "c"})
+	if got := RenderToString(Template("%v", view), 10, 0); got != "0: z\n1: a\n2: b\n3: c\n" {
+		t.Errorf("Expected the new item prepended, got %q", got)
+	}
+	if len(built) != 1 || built[0] != "z" {
+		t.Errorf("Expected only the prepended row built, got %v", built)
+	}
+
+	// A changed element rebuilds its row only; removed rows are dropped
+	built = nil
+	items.Set([]string{"a", "x"})
+	if got := RenderToString(Template("%v", view), 10, 0); got != "0: a\n1: x\n" {
+		t.Errorf("Expected the list updated, got %q", got)
+	}
+	if len(built) != 1 || built[0] != "x" {
+		t.Errorf("Expected only the changed row built, got %v", built)
+	}
+}
+
+func TestForKeyKeepsRowsOfUnchangedKeys(t *testing.T) {
+	type todo struct {
+		id    int
+		title string
+		tags  []string
+	}
+	items := signals.New([]todo{{1, "one", nil}, {2, "two", nil}})
+	built := 0
+	view := ForKey(items, func(item todo) int { return item.id }, func(item todo, index signals.Getter) *LayoutNode {
+		built++
+		return Text(item.title)
+	})
+
+	if got := RenderToString(Template("%v", view), 10, 0); got != "one\ntwo\n" {
+		t.Errorf("Expected one row per item, got %q", got)
+	}
+	built = 0
+	items.Set([]todo{{2, "two", []string{"x"}}, {1, "one", nil}, {2, "two", nil}})
+	if got := RenderToString(Template("%v", view), 10, 0); got != "two\none\ntwo\n" {
+		t.Errorf("Expected the rows reordered, got %q", got)
+	}
+	if built != 1 {
+		t.Errorf("Expected only the second row with key 2 built, got %d builds", built)
+	}
+}
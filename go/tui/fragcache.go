@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"basement/basement"
+	"container/list"
+	"sync"
+)
+
+// fragmentCacheSize bounds how many parsed hole values are kept
+const fragmentCacheSize = 256
+
+// fragmentCache is an LRU of ASTs for markup produced by hole values, so a
+// value that cycles through a few strings (a clock, a progress bar) isn't
+// re-parsed on every frame. Cached ASTs are shared and must not be modified.
+type fragmentCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // Front is most recently used
+	items map[string]*list.Element
+}
+
+type fragment struct {
+	key  string
+	root *basement.Node
+}
+
+func newFragmentCache(size int) *fragmentCache {
+	return &fragmentCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// parse returns the AST for markup, parsing it only on a cache miss
+func (c *fragmentCache) parse(markup string) *basement.Node {
+	c.mu.Lock()
+	if el, ok := c.items[markup]; ok {
+		c.order.MoveToFront(el)
+		c.mu.Unlock()
+		return el.Value.(*fragment).root
+	}
+	c.mu.Unlock()
+
+	// Parse outside the lock; a concurrent miss on the same key just parses twice
+	root := basement.ParseAST(markup)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[markup]; ok {
+		c.order.MoveToFront(el)
+		return el.Value.(*fragment).root
+	}
+	c.items[markup] = c.order.PushFront(&fragment{key: markup, root: root})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*fragment).key)
+	}
+	return root
+}
+
+var holeFragments = newFragmentCache(fragmentCacheSize)
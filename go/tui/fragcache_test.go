@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFragmentCacheEvictsOldest(t *testing.T) {
+	c := newFragmentCache(2)
+	a := c.parse("**a**")
+	c.parse("**b**")
+	if c.parse("**a**") != a {
+		t.Errorf("Expected a cache hit to return the same AST")
+	}
+
+	// "**b**" is now least recently used
+	c.parse("**c**")
+	if _, ok := c.items["**b**"]; ok {
+		t.Errorf("Expected the least recently used entry to be evicted")
+	}
+	if len(c.items) != 2 {
+		t.Errorf("Expected 2 entries, got %d", len(c.items))
+	}
+}
+
+func TestColoredHoleOutputUnchanged(t *testing.T) {
+	// Rendering the same value twice (the second from the cache) draws the same
+	for i := 0; i < 2; i++ {
+		s := newTestScreen(20, 1)
+		r := Template("Time: %v", "#green(12:00)")
+		renderNode(s, r.Root, r.Args, 0, 0)
+		if got := s.Back.String(); got != "Time: 12:00\n" {
+			t.Errorf("Render %d: expected %q, got %q", i, "Time: 12:00\n", got)
+		}
+		if c := s.Back.Get(6, 0); c.Style.Color != "\x1b[32m" {
+			t.Errorf("Render %d: expected green, got %q", i, c.Style.Color)
+		}
+	}
+}
+
+func BenchmarkChangingColoredHole(b *testing.B) {
+	s := newTestScreen(40, 1)
+	values := make([]string, 60)
+	for i := range values {
+		values[i] = fmt.Sprintf("#green(12:00:%02d)", i)
+	}
+	for i := 0; i < b.N; i++ {
+		r := Template("Time: %v", values[i%len(values)])
+		renderNode(s, r.Root, r.Args, 0, 0)
+	}
+}
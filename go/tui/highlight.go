@@ -1,9 +1,54 @@
 package tui
 
-import "basement/basement"
+import (
+	"basement/basement"
+	"strconv"
+	"strings"
+)
 
 // Span represents a styled segment of text
 type Span struct {
 	Text  string
 	Style basement.Style
 }
+
+// lineSet is a set of 1-based line numbers, held as inclusive ranges
+type lineSet [][2]int
+
+// parseLineSet parses a code fence's highlight attribute, a comma separated
+// list of line numbers and ranges ("2,4-6"). Malformed entries are ignored.
+func parseLineSet(spec string) lineSet {
+	var set lineSet
+	for _, part := range strings.Split(spec, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		first, err := strconv.Atoi(from)
+		if err != nil {
+			continue
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(to); err != nil {
+				continue
+			}
+		}
+		set = append(set, [2]int{first, last})
+	}
+	return set
+}
+
+// has reports whether line l is in the set
+func (ls lineSet) has(l int) bool {
+	for _, r := range ls {
+		if l >= r[0] && l <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// emphasize restyles a span on a highlighted code line: bold, never dim
+func emphasize(st basement.Style) basement.Style {
+	st.Dim = false
+	st.Bold = true
+	return st
+}
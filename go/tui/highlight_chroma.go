@@ -4,12 +4,38 @@ package tui
 
 import (
 	"basement/basement"
+	"sync"
 
 	"github.com/alecthomas/chroma"
 	"github.com/alecthomas/chroma/lexers"
 	"github.com/alecthomas/chroma/styles"
 )
 
+// defaultHighlightTheme is used when no theme (or an unknown one) is configured.
+// Monokai is a safe default for dark terminals.
+const defaultHighlightTheme = "monokai"
+
+var (
+	themeMu        sync.RWMutex
+	highlightTheme = defaultHighlightTheme
+)
+
+// SetHighlightTheme selects the Chroma style used for code blocks (e.g. "github", "dracula").
+// Unknown names fall back to the default theme.
+func SetHighlightTheme(name string) {
+	theme := defaultHighlightTheme
+	for _, known := range styles.Names() {
+		if known == name {
+			theme = name
+			break
+		}
+	}
+
+	themeMu.Lock()
+	highlightTheme = theme
+	themeMu.Unlock()
+}
+
 // Highlight returns a list of styled spans for the given code and language using Chroma.
 func Highlight(code, lang string) []Span {
 	// 1. Get Lexer
@@ -22,8 +48,12 @@ func Highlight(code, lang string) []Span {
 	}
 	lexer = chroma.Coalesce(lexer)
 
-	// 2. Get Style (Monokai is a safe default for dark terminals)
-	style := styles.Get("monokai")
+	// 2. Get Style from the configured theme
+	themeMu.RLock()
+	theme := highlightTheme
+	themeMu.RUnlock()
+
+	style := styles.Get(theme)
 	if style == nil {
 		style = styles.Fallback
 	}
@@ -0,0 +1,21 @@
+//go:build chroma
+
+package tui
+
+import (
+	"testing"
+)
+
+func TestSetHighlightTheme(t *testing.T) {
+	defer SetHighlightTheme(defaultHighlightTheme)
+
+	SetHighlightTheme("github")
+	if highlightTheme != "github" {
+		t.Errorf("Expected theme github, got %q", highlightTheme)
+	}
+
+	SetHighlightTheme("no-such-theme")
+	if highlightTheme != defaultHighlightTheme {
+		t.Errorf("Expected fallback to %q, got %q", defaultHighlightTheme, highlightTheme)
+	}
+}
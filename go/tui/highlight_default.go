@@ -11,3 +11,6 @@ func Highlight(code, lang string) []Span {
 		{Text: code, Style: basement.Style{Dim: true}},
 	}
 }
+
+// SetHighlightTheme is a no-op without the chroma build tag.
+func SetHighlightTheme(name string) {}
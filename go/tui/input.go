@@ -31,6 +31,12 @@ func inputLoop(ch chan<- KeyEvent, done <-chan struct{}) {
 		}
 	}()
 
+	decodeInput(rawCh, ch, done)
+}
+
+// decodeInput turns raw bytes into key events on ch until rawCh closes or
+// done fires, then closes ch
+func decodeInput(rawCh <-chan byte, ch chan<- KeyEvent, done <-chan struct{}) {
 	for {
 		select {
 		case <-done:
@@ -50,6 +56,53 @@ func inputLoop(ch chan<- KeyEvent, done <-chan struct{}) {
 	}
 }
 
+// keyBatchWindow is how long OnKeyBatched keeps collecting events after the
+// first one, about a frame at 60fps
+const keyBatchWindow = 16 * time.Millisecond
+
+// batchKeys passes the events from in to fn in batches: each batch holds an
+// event and any that follow within window of it. It returns when in closes.
+func batchKeys(in <-chan KeyEvent, window time.Duration, fn func([]KeyEvent)) {
+	for ev := range in {
+		batch := []KeyEvent{ev}
+		timer := time.NewTimer(window)
+	collect:
+		for {
+			select {
+			case ev, ok := <-in:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, ev)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+		fn(batch)
+	}
+}
+
+// CoalesceKeys collapses each run of identical consecutive events into one, so
+// a held arrow key moves a selection once per batch rather than once per
+// repeat:
+//
+//	screen.OnKeyBatched(func(evs []tui.KeyEvent) {
+//		for _, ev := range tui.CoalesceKeys(evs) {
+//			list.HandleKey(ev)
+//		}
+//	})
+func CoalesceKeys(events []KeyEvent) []KeyEvent {
+	var out []KeyEvent
+	for i, ev := range events {
+		if i > 0 && ev == events[i-1] {
+			continue
+		}
+		out = append(out, ev)
+	}
+	return out
+}
+
 // processEsc handles ESC byte and potential escape sequences.
 // Reads additional bytes from rawCh (not from the reader) to avoid races.
 func processEsc(rawCh <-chan byte, ch chan<- KeyEvent) {
@@ -149,6 +202,14 @@ func dispatchCSI(params []byte, final byte, ch chan<- KeyEvent) {
 		ch <- KeyEvent{Key: KeyHome}
 	case 'F':
 		ch <- KeyEvent{Key: KeyEnd}
+	case 'Z':
+		// Back-tab
+		ch <- KeyEvent{Key: KeyTab, Mod: ModShift}
+	case 'I':
+		// Focus reporting (ESC [ ? 1004 h)
+		ch <- KeyEvent{Key: keyFocusIn}
+	case 'O':
+		ch <- KeyEvent{Key: keyFocusOut}
 	case '~':
 		// Tilde-terminated: the first param encodes the key
 		// Strip modifier after semicolon (e.g. "3;5" → "3")
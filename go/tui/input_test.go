@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpaceIsKeyChar(t *testing.T) {
+	events := make(chan KeyEvent, 1)
+	processChar(0x20, events)
+	if ev := <-events; ev != (KeyEvent{Key: KeyChar, Rune: ' '}) {
+		t.Errorf("Expected space as KeyChar ' ', got %+v", ev)
+	}
+}
+
+func TestParseCSIBackTab(t *testing.T) {
+	raw := make(chan byte, 1)
+	events := make(chan KeyEvent, 1)
+
+	// ESC [ has already been read
+	raw <- 'Z'
+	parseCSI(raw, events)
+
+	select {
+	case ev := <-events:
+		if ev.Key != KeyTab || ev.Mod != ModShift {
+			t.Errorf("Expected Shift+Tab, got %+v", ev)
+		}
+	default:
+		t.Fatal("Expected an event for ESC [ Z")
+	}
+}
+
+func TestKeyRepeatCoalesces(t *testing.T) {
+	burst := strings.Repeat("\x1b[B", 5) + "\x1b[A\x1b[Aq"
+	raw := make(chan byte, len(burst))
+	for i := 0; i < len(burst); i++ {
+		raw <- burst[i]
+	}
+	close(raw)
+
+	events := make(chan KeyEvent)
+	go decodeInput(raw, events, nil)
+
+	var batches [][]KeyEvent
+	batchKeys(events, time.Second, func(evs []KeyEvent) {
+		batches = append(batches, evs)
+	})
+	if len(batches) != 1 || len(batches[0]) != 8 {
+		t.Fatalf("Expected the burst as one batch of 8 events, got %v", batches)
+	}
+	got := CoalesceKeys(batches[0])
+	want := []KeyEvent{{Key: KeyArrowDown}, {Key: KeyArrowUp}, {Key: KeyChar, Rune: 'q'}}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d coalesced events, got %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Event %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestFocusReports(t *testing.T) {
+	raw := make(chan byte, 6)
+	for _, b := range []byte("\x1b[I\x1b[O") {
+		raw <- b
+	}
+	close(raw)
+	events := make(chan KeyEvent)
+	go decodeInput(raw, events, nil)
+
+	s := newHeadlessScreen(1, 1, io.Discard)
+	focus := make(chan FocusEvent, 2)
+	s.OnFocus(func(ev FocusEvent) { focus <- ev })
+	for ev := range s.splitFocus(events) {
+		t.Errorf("Expected no key events, got %+v", ev)
+	}
+	if got := []FocusEvent{<-focus, <-focus}; got[0] != (FocusEvent{Focused: true}) || got[1] != (FocusEvent{Focused: false}) {
+		t.Errorf("Expected focus in then out, got %+v", got)
+	}
+}
+
+func TestFocusReportsPassUnreadKeys(t *testing.T) {
+	events := make(chan KeyEvent)
+	s := newHeadlessScreen(1, 1, io.Discard)
+	s.doneChan = make(chan struct{})
+	focus := make(chan FocusEvent, 1)
+	s.OnFocus(func(ev FocusEvent) { focus <- ev })
+	out := s.splitFocus(events)
+
+	// Nobody reads the key, yet the focus report behind it is delivered
+	events <- KeyEvent{Key: KeyChar, Rune: 'a'}
+	events <- KeyEvent{Key: keyFocusIn}
+	if got := <-focus; got != (FocusEvent{Focused: true}) {
+		t.Errorf("Expected focus in, got %+v", got)
+	}
+	if ev := <-out; ev.Rune != 'a' {
+		t.Errorf("Expected the queued key, got %+v", ev)
+	}
+
+	// Closing the screen ends forwarding with keys still queued
+	events <- KeyEvent{Key: KeyChar, Rune: 'b'}
+	close(s.doneChan)
+	for range out {
+	}
+}
@@ -9,6 +9,9 @@ const (
 	KeyBackspace
 	KeyTab
 	KeyEsc
+
+	// Deprecated: the space bar arrives as KeyChar with Rune ' ', like any
+	// other printable character. KeySpace is never produced.
 	KeySpace
 
 	// Cursor movement
@@ -41,6 +44,10 @@ const (
 
 	// Char represents a regular rune key
 	KeyChar
+
+	// Window focus reports, turned into FocusEvents by the Screen
+	keyFocusIn
+	keyFocusOut
 )
 
 // Mod represents modifier keys (Ctrl, Alt, Shift)
@@ -53,9 +60,16 @@ const (
 	ModShift Mod = 1 << 2
 )
 
-// KeyEvent represents a keyboard event
+// KeyEvent represents a keyboard event. Printable characters, space
+// included, have Key KeyChar and the character in Rune.
 type KeyEvent struct {
 	Key  Key
 	Rune rune
 	Mod  Mod
 }
+
+// FocusEvent reports the terminal window gaining or losing focus, see
+// Screen.OnFocus
+type FocusEvent struct {
+	Focused bool
+}
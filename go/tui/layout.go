@@ -8,6 +8,15 @@ const (
 	DirColumn
 )
 
+// Align defines where content sits along an axis when its box has spare room
+type Align int
+
+const (
+	AlignStart  Align = iota // Left / Top
+	AlignCenter              // Centered
+	AlignEnd                 // Right / Bottom
+)
+
 // SizeType defines how a node is sized
 type SizeType int
 
@@ -41,13 +50,18 @@ func Auto() Size {
 // LayoutNode represents a node in the layout tree.
 // Uses a doubly linked list structure (inspired by LinkeDOM) instead of
 // child slices for O(1) insertions and zero slice allocations.
+// Measurements of static subtrees are cached: call MarkDirty after changing
+// a node's fields directly (the With* builders do it for you).
 type LayoutNode struct {
 	Direction Direction
 	Width     Size
 	Height    Size
 	Padding   int
 	Border    bool
+	AlignH    Align       // Horizontal placement of children within the content area
+	AlignV    Align       // Vertical placement of children within the content area
 	Content   interface{} // For leaf nodes: string, Renderable, or Signal
+	spacer    bool        // Flexible gap, see Spacer
 
 	// Linked list pointers
 	Parent     *LayoutNode
@@ -59,4 +73,11 @@ type LayoutNode struct {
 	// Calculated during Measure pass
 	computedX, computedY int
 	computedW, computedH int
+
+	// Measure cache: a static subtree (no signal-backed content) measured
+	// again with the same constraints reuses computedW/H
+	measured     bool
+	dirty        bool // Changed since the last Measure; see MarkDirty
+	static       bool
+	lastW, lastH int // Constraints of the last Measure
 }
\ No newline at end of file
@@ -1,5 +1,7 @@
 package tui
 
+import "basement/basement"
+
 // Row creates a horizontal layout node
 func Row(children ...interface{}) *LayoutNode {
 	n := &LayoutNode{
@@ -39,22 +41,69 @@ func Box(child interface{}, border bool, padding int) *LayoutNode {
 	return n
 }
 
+// Text creates a leaf node that renders basement markup (e.g. "#green(ok)")
+// with its styles applied, instead of drawing the raw syntax.
+// The markup is parsed once, when the node is created.
+func Text(markup string) *LayoutNode {
+	return &LayoutNode{
+		Width:   Auto(),
+		Height:  Auto(),
+		Content: basement.ParseAST(markup),
+	}
+}
+
+// Spacer creates an empty node that absorbs the leftover space along its
+// parent's direction, pushing its siblings apart: Row(title, Spacer(), status)
+func Spacer() *LayoutNode {
+	return &LayoutNode{
+		Width:  Flex(1),
+		Height: Flex(1),
+		spacer: true,
+	}
+}
+
+// Center fills the available space and places child in its middle
+func Center(child *LayoutNode) *LayoutNode {
+	return Box(child, false, 0).WithSize(Flex(1), Flex(1)).WithAlign(AlignCenter, AlignCenter)
+}
+
+// CenterH fills the available width and centers child horizontally
+func CenterH(child *LayoutNode) *LayoutNode {
+	return Box(child, false, 0).WithWidth(Flex(1)).WithAlign(AlignCenter, AlignStart)
+}
+
+// CenterV fills the available height and centers child vertically
+func CenterV(child *LayoutNode) *LayoutNode {
+	return Box(child, false, 0).WithHeight(Flex(1)).WithAlign(AlignStart, AlignCenter)
+}
+
 // WithSize sets the size constraints for a node
 func (n *LayoutNode) WithSize(w, h Size) *LayoutNode {
 	n.Width = w
 	n.Height = h
+	n.MarkDirty()
 	return n
 }
 
 // WithWidth sets the width constraint
 func (n *LayoutNode) WithWidth(w Size) *LayoutNode {
 	n.Width = w
+	n.MarkDirty()
 	return n
 }
 
 // WithHeight sets the height constraint
 func (n *LayoutNode) WithHeight(h Size) *LayoutNode {
 	n.Height = h
+	n.MarkDirty()
+	return n
+}
+
+// WithAlign sets how children are placed when the node is larger than its content
+func (n *LayoutNode) WithAlign(h, v Align) *LayoutNode {
+	n.AlignH = h
+	n.AlignV = v
+	n.MarkDirty()
 	return n
 }
 
@@ -69,6 +118,7 @@ func (n *LayoutNode) addChild(child *LayoutNode) {
 		n.FirstChild = child
 	}
 	n.LastChild = child
+	n.MarkDirty()
 }
 
 // wrapChild ensures a value is represented as a *LayoutNode.
@@ -5,7 +5,6 @@ import (
 	"basement/signals"
 	"fmt"
 	"strings"
-	"unicode/utf8"
 )
 
 // effectiveNode resolves a child node for layout purposes.
@@ -24,8 +23,92 @@ func effectiveNode(child *LayoutNode) *LayoutNode {
 }
 
 // Measure calculates the dimensions of the layout tree.
-// It populates the computed fields in LayoutNode.
+// It populates the computed fields in LayoutNode. A subtree with only static
+// content (strings, parsed markup) that hasn't been marked dirty is not
+// remeasured when the constraints are the same as last time.
 func (n *LayoutNode) Measure(constraintW, constraintH int) (int, int) {
+	if n.measured && !n.dirty && n.static && constraintW == n.lastW && constraintH == n.lastH {
+		return n.computedW, n.computedH
+	}
+
+	w, h := n.measure(constraintW, constraintH)
+
+	n.measured, n.dirty = true, false
+	n.lastW, n.lastH = constraintW, constraintH
+	n.static = n.isStatic()
+	return w, h
+}
+
+// MarkDirty discards the cached measurement of n and its ancestors.
+// Call it after changing a node's fields or content directly.
+func (n *LayoutNode) MarkDirty() {
+	for p := n; p != nil; p = p.Parent {
+		p.dirty = true
+	}
+}
+
+// sameSize reports whether n measures as it did last time. Only signal-backed
+// or dirty subtrees are measured again, with the last constraints.
+func (n *LayoutNode) sameSize() bool {
+	if !n.measured {
+		return false
+	}
+	if n.static && !n.dirty {
+		return true
+	}
+	w, h := n.computedW, n.computedH
+	nw, nh := n.Measure(n.lastW, n.lastH)
+	return nw == w && nh == h
+}
+
+// isStatic reports whether n's size can only change through MarkDirty: its
+// content isn't signal-backed and all its children were static when measured.
+func (n *LayoutNode) isStatic() bool {
+	if n.Content != nil && !staticContent(n.Content) {
+		return false
+	}
+	for child := n.FirstChild; child != nil; child = child.Next {
+		if child.Content != nil {
+			if !staticContent(child.Content) {
+				return false
+			}
+		} else if !child.static {
+			return false
+		}
+	}
+	return true
+}
+
+// staticContent reports whether content always measures the same
+func staticContent(v interface{}) bool {
+	switch v.(type) {
+	case string, Raw, *basement.Node:
+		return true
+	}
+	return false
+}
+
+// measure does the work of Measure
+func (n *LayoutNode) measure(constraintW, constraintH int) (int, int) {
+	// A content leaf measured on its own (e.g. Text used directly as a hole value)
+	if n.Content != nil && n.FirstChild == nil {
+		val := resolveValue(n.Content)
+		if node, ok := val.(*LayoutNode); ok {
+			n.computedW, n.computedH = node.Measure(constraintW, constraintH)
+		} else {
+			n.computedW, n.computedH = measureContent(val, constraintW, constraintH)
+		}
+		return n.computedW, n.computedH
+	}
+
+	// A fixed size holds on either axis, not just along the parent's direction
+	if n.Width.Type == SizeFixed && n.Width.Value < constraintW {
+		constraintW = n.Width.Value
+	}
+	if n.Height.Type == SizeFixed && n.Height.Value < constraintH {
+		constraintH = n.Height.Value
+	}
+
 	// 1. Determine available space for content (Box Model: Border-Box)
 	horizontalDeduction := n.Padding * 2
 	verticalDeduction := n.Padding * 2
@@ -134,6 +217,15 @@ func (n *LayoutNode) Measure(constraintW, constraintH int) (int, int) {
 				}
 				child.computedW = w
 				child.computedH = h
+
+				// A spacer only takes room along the direction
+				if node.spacer {
+					if n.Direction == DirRow {
+						child.computedH = 0
+					} else {
+						child.computedW = 0
+					}
+				}
 			}
 		}
 
@@ -180,6 +272,17 @@ func (n *LayoutNode) Draw(screen *Screen, x, y int) {
 	n.computedX = x
 	n.computedY = y
 
+	// A content leaf drawn on its own
+	if n.Content != nil && n.FirstChild == nil {
+		val := resolveValue(n.Content)
+		if node, ok := val.(*LayoutNode); ok {
+			node.Draw(screen, x, y)
+		} else {
+			drawContent(screen, val, x, y, n.computedW, n.computedH)
+		}
+		return
+	}
+
 	// Draw Border
 	if n.Border {
 		drawBorder(screen, x, y, n.computedW, n.computedH)
@@ -193,8 +296,18 @@ func (n *LayoutNode) Draw(screen *Screen, x, y int) {
 		contentY++
 	}
 
+	// Clip children to the content area so oversized content cannot
+	// paint over the border or neighbouring siblings
+	contentW := n.computedW - (contentX-x)*2
+	contentH := n.computedH - (contentY-y)*2
+	screen.Back.PushClip(Rect{X: contentX, Y: contentY, W: contentW, H: contentH})
+	defer screen.Back.PopClip()
+
+	// Align the children as a block within the content area
+	offX, offY := n.alignOffset(contentW, contentH)
+
 	// Draw Children
-	curX, curY := contentX, contentY
+	curX, curY := contentX+offX, contentY+offY
 
 	for child := n.FirstChild; child != nil; child = child.Next {
 		if child.Content != nil {
@@ -219,6 +332,41 @@ func (n *LayoutNode) Draw(screen *Screen, x, y int) {
 	}
 }
 
+// alignOffset returns how far to shift the children so they honour AlignH/AlignV
+// inside a content area of availW x availH.
+func (n *LayoutNode) alignOffset(availW, availH int) (int, int) {
+	if n.AlignH == AlignStart && n.AlignV == AlignStart {
+		return 0, 0
+	}
+
+	// Extent of the children: summed along the direction, max across it
+	var childW, childH int
+	for child := n.FirstChild; child != nil; child = child.Next {
+		if n.Direction == DirRow {
+			childW += child.computedW
+			if child.computedH > childH { childH = child.computedH }
+		} else {
+			childH += child.computedH
+			if child.computedW > childW { childW = child.computedW }
+		}
+	}
+
+	return alignDelta(n.AlignH, availW-childW), alignDelta(n.AlignV, availH-childH)
+}
+
+func alignDelta(a Align, spare int) int {
+	if spare <= 0 {
+		return 0
+	}
+	switch a {
+	case AlignCenter:
+		return spare / 2
+	case AlignEnd:
+		return spare
+	}
+	return 0
+}
+
 func resolveValue(v interface{}) interface{} {
 	if s, ok := v.(signals.Getter); ok {
 		return s.GetValue()
@@ -231,6 +379,9 @@ func extractText(n *basement.Node) string {
 	if n.Type == basement.NodeText {
 		return n.Content
 	}
+	if n.Type == basement.NodeImage {
+		return imageText(n)
+	}
 	var b strings.Builder
 	for _, child := range n.Children {
 		b.WriteString(extractText(child))
@@ -238,29 +389,59 @@ func extractText(n *basement.Node) string {
 	return b.String()
 }
 
-func measureContent(v interface{}, maxW, maxH int) (int, int) {
-	s := fmt.Sprintf("%v", v)
-
-	// If string contains markup, measure the rendered text, not the raw syntax.
-	// e.g. "#green(Hello)" should measure as 5 chars, not 13.
-	if containsMarkup(s) {
-		root := basement.ParseAST(s)
-		s = extractText(root)
+// measureAST returns the display size of a parsed markup tree as renderNode draws it:
+// one line per block, list item or code line, ignoring the raw markup syntax.
+func measureAST(root *basement.Node) (int, int) {
+	w, h := 0, 0
+	line := func(width int) {
+		if width > w {
+			w = width
+		}
+		h++
 	}
 
-	// Handle newlines for correct measurement
-	lines := strings.Split(s, "\n")
-
-	maxLineLen := 0
-	for _, line := range lines {
-		l := utf8.RuneCountInString(line)
-		if l > maxLineLen {
-			maxLineLen = l
+	for _, child := range root.Children {
+		switch child.Type {
+		case basement.NodeCodeBlock:
+			for _, l := range strings.Split(strings.TrimSuffix(expandTabs(child.Content, 0), "\n"), "\n") {
+				line(stringWidth(l) + 2*codePadding)
+			}
+		case basement.NodeList:
+			for _, item := range child.Children {
+				line(stringWidth(extractText(item)) + 2) // bullet + space
+			}
+		case basement.NodeQuote:
+			line(stringWidth(extractText(child)) + quoteDepth(child)*2) // bar + space per level
+		default:
+			line(stringWidth(extractText(child)))
 		}
 	}
+	return w, h
+}
 
-	w := maxLineLen
-	h := len(lines)
+func measureContent(v interface{}, maxW, maxH int) (int, int) {
+	var w, h int
+
+	if root, ok := v.(*basement.Node); ok {
+		w, h = measureAST(root)
+	} else if raw, ok := v.(Raw); ok {
+		// Measure the text, not the escapes
+		w, h = measureSpans(ParseANSI(string(raw)))
+	} else if s := fmt.Sprintf("%v", v); containsMarkup(s) {
+		// If string contains markup, measure the rendered text, not the raw syntax.
+		// e.g. "#green(Hello)" should measure as 5 chars, not 13.
+		w, h = measureAST(basement.ParseAST(s))
+	} else {
+		// Handle newlines for correct measurement, tabs as drawn
+		lines := strings.Split(expandTabs(s, 0), "\n")
+		for _, line := range lines {
+			l := stringWidth(line)
+			if l > w {
+				w = l
+			}
+		}
+		h = len(lines)
+	}
 
 	if w > maxW { w = maxW }
 	if h > maxH { h = maxH }
@@ -268,20 +449,59 @@ func measureContent(v interface{}, maxW, maxH int) (int, int) {
 	return w, h
 }
 
+// measureSpans returns the size of spans drawn as text, with tabs expanded
+// where each piece of a line starts, as drawSpansUnlocked does
+func measureSpans(spans []Span) (int, int) {
+	w, h, col := 0, 1, 0
+	for _, span := range spans {
+		text := span.Text
+		for {
+			i := strings.IndexByte(text, '\n')
+			piece := text
+			if i >= 0 {
+				piece = text[:i]
+			}
+			col += stringWidth(expandTabs(piece, col))
+			if col > w {
+				w = col
+			}
+			if i < 0 {
+				break
+			}
+			text = text[i+1:]
+			col, h = 0, h+1
+		}
+	}
+	return w, h
+}
+
 func drawContent(screen *Screen, v interface{}, x, y, w, h int) {
+	// Pre-parsed markup (from Text): render with the main render engine
+	if root, ok := v.(*basement.Node); ok {
+		drawAST(screen, root, x, y, w, h)
+		return
+	}
+	if raw, ok := v.(Raw); ok {
+		// Tab stops count from the box's edge, as measured
+		origin := screen.originX
+		screen.originX = -x
+		screen.Back.PushClip(Rect{X: x, Y: y, W: w, H: h})
+		screen.drawRawUnlocked(x, y, raw, basement.Style{})
+		screen.Back.PopClip()
+		screen.originX = origin
+		return
+	}
+
 	s := fmt.Sprintf("%v", v)
 
 	// Check for markup
 	if containsMarkup(s) {
-		// Parse and render using the main render engine
-		root := basement.ParseAST(s)
-		// Use renderNode which uses drawTextUnlocked
-		renderNode(screen, root, nil, x, y)
+		drawAST(screen, basement.ParseAST(s), x, y, w, h)
 		return
 	}
 
-	// Handle newlines
-	lines := strings.Split(s, "\n")
+	// Handle newlines; expand tabs first so truncation sees their width
+	lines := strings.Split(expandTabs(s, 0), "\n")
 
 	for i, line := range lines {
 		if i >= h {
@@ -289,16 +509,21 @@ func drawContent(screen *Screen, v interface{}, x, y, w, h int) {
 		}
 
 		// Truncate line if too long
-		if utf8.RuneCountInString(line) > w {
-			runes := []rune(line)
-			line = string(runes[:w])
-		}
+		line = truncateWidth(line, w)
 
 		// Use unlocked version since we are inside Frame()
 		screen.drawTextUnlocked(x, y+i, line, basement.Style{})
 	}
 }
 
+// drawAST renders a parsed markup tree clipped to the w x h box at x, y
+func drawAST(screen *Screen, root *basement.Node, x, y, w, h int) {
+	screen.Back.PushClip(Rect{X: x, Y: y, W: w, H: h})
+	// Use renderNode which uses drawTextUnlocked
+	renderNode(screen, root, nil, x, y)
+	screen.Back.PopClip()
+}
+
 func drawBorder(screen *Screen, x, y, w, h int) {
 	// Unicode box drawing
 	// ┌─┐
@@ -0,0 +1,191 @@
+package tui
+
+import (
+	"basement/signals"
+	"io"
+	"testing"
+)
+
+// newTestScreen returns a Screen with buffers only, detached from the terminal
+func newTestScreen(w, h int) *Screen {
+	return newHeadlessScreen(w, h, io.Discard)
+}
+
+func TestDrawClipsOversizedChild(t *testing.T) {
+	s := newTestScreen(40, 10)
+
+	child := Box("this line is far too wide for the parent", true, 0).WithWidth(Fixed(30))
+	parent := Box(child, true, 0).WithSize(Fixed(10), Fixed(5))
+
+	parent.Measure(10, 5)
+	parent.Draw(s, 0, 0)
+
+	// The parent's right border must survive the child's top border
+	if got := s.Back.Get(9, 1).Char; got != '│' {
+		t.Errorf("Expected parent border at (9,1), got %q", got)
+	}
+
+	// Nothing may be painted beyond the parent's box
+	for y := 0; y < 10; y++ {
+		for x := 10; x < 40; x++ {
+			if c := s.Back.Get(x, y).Char; c != 0 {
+				t.Fatalf("Child painted outside parent at (%d,%d): %q", x, y, c)
+			}
+		}
+	}
+}
+
+func TestTextRendersMarkup(t *testing.T) {
+	s := newTestScreen(20, 3)
+
+	node := Box(Text("#green(hi)"), false, 0)
+	w, h := node.Measure(20, 3)
+	if w != 2 || h != 1 {
+		t.Errorf("Expected Text to measure 2x1, got %dx%d", w, h)
+	}
+
+	node.Draw(s, 0, 0)
+
+	for i, want := range "hi" {
+		cell := s.Back.Get(i, 0)
+		if cell.Char != want {
+			t.Errorf("Cell %d: expected %q, got %q", i, want, cell.Char)
+		}
+		if cell.Style.Color != "\x1b[32m" {
+			t.Errorf("Cell %d: expected green, got %q", i, cell.Style.Color)
+		}
+	}
+	if c := s.Back.Get(2, 0).Char; c != 0 {
+		t.Errorf("Expected nothing after the text, got %q", c)
+	}
+}
+
+func TestBoxAlignment(t *testing.T) {
+	tests := []struct {
+		name  string
+		h, v  Align
+		wantX int
+		wantY int
+	}{
+		{"start", AlignStart, AlignStart, 1, 1},
+		{"center", AlignCenter, AlignCenter, 4, 3},
+		{"end", AlignEnd, AlignEnd, 7, 5},
+	}
+
+	for _, tt := range tests {
+		s := newTestScreen(20, 10)
+
+		// 10x7 bordered box leaves an 8x5 content area for a 2x1 child
+		node := Box("42", true, 0).WithSize(Fixed(10), Fixed(7)).WithAlign(tt.h, tt.v)
+		node.Measure(10, 7)
+		node.Draw(s, 0, 0)
+
+		if c := s.Back.Get(tt.wantX, tt.wantY).Char; c != '4' {
+			t.Errorf("%s: expected child at (%d,%d), got %q", tt.name, tt.wantX, tt.wantY, c)
+		}
+	}
+}
+
+func TestMeasureCachesStaticSubtree(t *testing.T) {
+	leaf := Box("abc", false, 0)
+	root := Row(leaf, Box("de", false, 0))
+
+	if w, _ := root.Measure(20, 5); w != 5 {
+		t.Fatalf("Expected width 5, got %d", w)
+	}
+
+	// Changing content behind the cache's back is not picked up...
+	leaf.FirstChild.Content = "abcdef"
+	if w, _ := root.Measure(20, 5); w != 5 {
+		t.Errorf("Expected the unchanged subtree not to be remeasured, got width %d", w)
+	}
+
+	// ...until the node is marked dirty, or the constraints change
+	leaf.FirstChild.MarkDirty()
+	if w, _ := root.Measure(20, 5); w != 8 {
+		t.Errorf("Expected MarkDirty to force a remeasure, got width %d", w)
+	}
+	if w, _ := root.Measure(3, 5); w != 5 { // Each child is clipped to 3
+		t.Errorf("Expected new constraints to force a remeasure, got width %d", w)
+	}
+}
+
+func TestMeasureSignalContentNotCached(t *testing.T) {
+	label := signals.New("ab")
+	root := Row(Box(label, false, 0))
+
+	root.Measure(20, 5)
+	label.Set("abcd")
+	if w, _ := root.Measure(20, 5); w != 4 {
+		t.Errorf("Expected signal-backed content to be remeasured, got width %d", w)
+	}
+}
+
+func BenchmarkMeasureStaticLayout(b *testing.B) {
+	var rows []interface{}
+	for i := 0; i < 50; i++ {
+		rows = append(rows, Row(Box("#green(label)", true, 1), Box("some value text", false, 0)))
+	}
+	root := Col(rows...)
+	for i := 0; i < b.N; i++ {
+		root.Measure(80, 200)
+	}
+}
+
+func TestDrawKeepsSiblingsIntact(t *testing.T) {
+	s := newTestScreen(20, 3)
+
+	left := Box("overflowing text", true, 0).WithSize(Fixed(6), Fixed(3))
+	right := Box("R", false, 0)
+	root := Row(left, right)
+	root.Measure(20, 3)
+	root.Draw(s, 0, 0)
+
+	if got := s.Back.String(); got != "┌────┐R\n│over│\n└────┘\n" {
+		t.Errorf("Expected the text clipped inside the border, got %q", got)
+	}
+}
+
+func TestCenter(t *testing.T) {
+	s := newTestScreen(80, 24)
+
+	child := Box("splash", true, 0).WithSize(Fixed(10), Fixed(3))
+	root := Center(child)
+	root.Measure(80, 24)
+	root.Draw(s, 0, 0)
+	if child.computedX != 35 || child.computedY != 10 {
+		t.Errorf("Expected child at (35,10), got (%d,%d)", child.computedX, child.computedY)
+	}
+
+	// Single-axis variants leave the other axis at the start
+	child = Box("x", false, 0).WithSize(Fixed(10), Fixed(3))
+	root = CenterH(child)
+	root.Measure(80, 24)
+	root.Draw(s, 0, 0)
+	if child.computedX != 35 || child.computedY != 0 {
+		t.Errorf("CenterH: expected child at (35,0), got (%d,%d)", child.computedX, child.computedY)
+	}
+
+	child = Box("x", false, 0).WithSize(Fixed(10), Fixed(3))
+	root = CenterV(child)
+	root.Measure(80, 24)
+	root.Draw(s, 0, 0)
+	if child.computedX != 0 || child.computedY != 10 {
+		t.Errorf("CenterV: expected child at (0,10), got (%d,%d)", child.computedX, child.computedY)
+	}
+}
+
+func TestSpacerPushesApart(t *testing.T) {
+	s := newTestScreen(20, 3)
+
+	root := Row("left", Spacer(), "right")
+	w, h := root.Measure(20, 3)
+	root.Draw(s, 0, 0)
+
+	if w != 20 || h != 1 {
+		t.Errorf("Expected the row to fill the width at one line high, got %dx%d", w, h)
+	}
+	if got := s.Back.String(); got != "left           right\n\n\n" {
+		t.Errorf("Expected right pushed to the edge, got %q", got)
+	}
+}
@@ -0,0 +1,136 @@
+package tui
+
+import "basement/signals"
+
+// List is a navigable list of items with a highlighted selection.
+// It implements signals.Getter, resolving to its *LayoutNode view, so it can be
+// placed straight into a Template hole or a layout and re-renders as the selection moves.
+type List struct {
+	items    []string
+	selected *signals.Signal[int]
+	wrap     bool
+	render   func(item string, selected bool) interface{}
+
+	// The column built for viewIdx. Measure and Draw each resolve the list,
+	// so both must get the same node.
+	view    *LayoutNode
+	viewIdx int
+
+	// OnSelect is called with the selected row when Enter is pressed
+	OnSelect func(index int, item string)
+}
+
+// NewList creates a list with the first item selected
+func NewList(items []string) *List {
+	return &List{
+		items:    items,
+		selected: signals.New(0),
+		render:   defaultListItem,
+	}
+}
+
+// WithWrap enables wrap-around: moving past either end jumps to the other
+func (l *List) WithWrap(wrap bool) *List {
+	l.wrap = wrap
+	return l
+}
+
+// WithRenderer replaces how each row is drawn. The returned value is placed
+// in the list's column like any layout child (string, *LayoutNode, ...).
+func (l *List) WithRenderer(fn func(item string, selected bool) interface{}) *List {
+	l.render = fn
+	l.view = nil
+	return l
+}
+
+// defaultListItem marks the selected row with "> " and colors it green
+func defaultListItem(item string, selected bool) interface{} {
+	if selected {
+		return Text("#green(> " + item + ")")
+	}
+	return "  " + item
+}
+
+// Index returns the signal holding the selected row
+func (l *List) Index() *signals.Signal[int] {
+	return l.selected
+}
+
+// Selected returns the index of the selected row
+func (l *List) Selected() int {
+	return l.selected.Get()
+}
+
+// SelectedItem returns the selected item, or "" for an empty list
+func (l *List) SelectedItem() string {
+	idx := l.selected.Get()
+	if idx < 0 || idx >= len(l.items) {
+		return ""
+	}
+	return l.items[idx]
+}
+
+// MoveUp selects the previous row
+func (l *List) MoveUp() {
+	l.move(-1)
+}
+
+// MoveDown selects the next row
+func (l *List) MoveDown() {
+	l.move(1)
+}
+
+func (l *List) move(delta int) {
+	n := len(l.items)
+	if n == 0 {
+		return
+	}
+	idx := l.selected.Peek() + delta
+	if l.wrap {
+		idx = (idx%n + n) % n
+	} else if idx < 0 {
+		idx = 0
+	} else if idx >= n {
+		idx = n - 1
+	}
+	l.selected.Set(idx)
+}
+
+// HandleKey applies Up/Down/Enter to the list. Returns true if the key was used.
+func (l *List) HandleKey(ev KeyEvent) bool {
+	switch ev.Key {
+	case KeyArrowUp:
+		l.MoveUp()
+	case KeyArrowDown:
+		l.MoveDown()
+	case KeyEnter:
+		if l.OnSelect != nil && len(l.items) > 0 {
+			idx := l.selected.Peek()
+			l.OnSelect(idx, l.items[idx])
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// View builds the list as a column with one row per item. The column is
+// reused until the selection moves.
+func (l *List) View() *LayoutNode {
+	idx := l.selected.Get()
+	if l.view != nil && idx == l.viewIdx {
+		return l.view
+	}
+
+	rows := make([]interface{}, len(l.items))
+	for i, item := range l.items {
+		rows[i] = l.render(item, i == idx)
+	}
+	l.view, l.viewIdx = Col(rows...), idx
+	return l.view
+}
+
+// GetValue implements the Getter interface
+func (l *List) GetValue() interface{} {
+	return l.View()
+}
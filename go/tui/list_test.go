@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"io"
+	"testing"
+)
+
+func TestListWrapAround(t *testing.T) {
+	l := NewList([]string{"a", "b", "c"}).WithWrap(true)
+
+	l.MoveDown()
+	l.MoveDown()
+	if l.Selected() != 2 {
+		t.Fatalf("Expected index 2, got %d", l.Selected())
+	}
+
+	l.MoveDown()
+	if l.Selected() != 0 {
+		t.Errorf("Expected MoveDown past the end to wrap to 0, got %d", l.Selected())
+	}
+
+	l.MoveUp()
+	if l.Selected() != 2 {
+		t.Errorf("Expected MoveUp past the start to wrap to 2, got %d", l.Selected())
+	}
+}
+
+func TestListClampAndSelect(t *testing.T) {
+	l := NewList([]string{"a", "b"})
+
+	l.MoveUp()
+	if l.Selected() != 0 {
+		t.Errorf("Expected index to stay at 0 without wrap, got %d", l.Selected())
+	}
+
+	var picked string
+	l.OnSelect = func(index int, item string) { picked = item }
+	l.HandleKey(KeyEvent{Key: KeyArrowDown})
+	l.HandleKey(KeyEvent{Key: KeyArrowDown})
+	l.HandleKey(KeyEvent{Key: KeyEnter})
+	if picked != "b" {
+		t.Errorf("Expected OnSelect with \"b\", got %q", picked)
+	}
+}
+
+func TestListView(t *testing.T) {
+	l := NewList([]string{"one", "two"})
+	l.MoveDown()
+
+	got := RenderToString(Template("%v", l), 10, 2)
+	if want := "  one\n> two\n"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestListInsideLayout(t *testing.T) {
+	l := NewList([]string{"one", "two"})
+	s := newHeadlessScreen(12, 5, io.Discard)
+	Render(s, func() Renderable { return Template("%v", Col("menu", Box(l, true, 0))) })
+	if got := s.Back.String(); got != "menu\n┌─────┐\n│> one│\n│  two│\n└─────┘\n" {
+		t.Fatalf("Expected the list drawn inside the box, got %q", got)
+	}
+
+	l.MoveDown()
+	if got := s.Back.String(); got != "menu\n┌─────┐\n│  one│\n│> two│\n└─────┘\n" {
+		t.Errorf("Expected the selection to move, got %q", got)
+	}
+}
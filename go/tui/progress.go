@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"basement/signals"
+	"strconv"
+	"strings"
+)
+
+// ProgressOption customises a ProgressBar
+type ProgressOption func(*progressConfig)
+
+type progressConfig struct {
+	fill        rune
+	empty       rune
+	showPercent bool
+	gradient    bool
+}
+
+// WithProgressRunes sets the runes used for the filled and empty parts of the bar
+func WithProgressRunes(fill, empty rune) ProgressOption {
+	return func(c *progressConfig) {
+		c.fill = fill
+		c.empty = empty
+	}
+}
+
+// WithPercent appends the percentage (e.g. " 42%") after the bar
+func WithPercent() ProgressOption {
+	return func(c *progressConfig) {
+		c.showPercent = true
+	}
+}
+
+// ProgressBar returns a computed bar of width cells for a 0-100 value.
+// Values outside the range are clamped. The bar re-computes whenever value changes,
+// so Template("%v", bar) stays up to date.
+func ProgressBar(value signals.Getter, width int, opts ...ProgressOption) *signals.Computed[string] {
+	cfg := progressConfig{fill: '█', empty: '░'}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return signals.NewComputed(func() string {
+		return cfg.render(toPercent(value.GetValue()), width)
+	})
+}
+
+// GradientProgressBar is a ProgressBar whose filled cells shade from red
+// through yellow to green along the length of the bar.
+func GradientProgressBar(value signals.Getter, width int, opts ...ProgressOption) *signals.Computed[string] {
+	return ProgressBar(value, width, append(opts, func(c *progressConfig) {
+		c.gradient = true
+	})...)
+}
+
+func (c progressConfig) render(percent, width int) string {
+	var b strings.Builder
+
+	if width > 0 {
+		filled := percent * width / 100
+		fill := string(c.fill)
+
+		if c.gradient {
+			// Group consecutive cells of the same color into one span
+			for i := 0; i < filled; {
+				color := gradientColor(i, width)
+				j := i
+				for j < filled && gradientColor(j, width) == color {
+					j++
+				}
+				b.WriteString("#" + color + "(" + strings.Repeat(fill, j-i) + ")")
+				i = j
+			}
+		} else {
+			b.WriteString(strings.Repeat(fill, filled))
+		}
+		b.WriteString(strings.Repeat(string(c.empty), width-filled))
+	}
+
+	if c.showPercent {
+		if width > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(strconv.Itoa(percent) + "%")
+	}
+	return b.String()
+}
+
+// gradientColor returns the color name for cell i of a bar width cells wide
+func gradientColor(i, width int) string {
+	switch {
+	case i*3 < width:
+		return "red"
+	case i*3 < width*2:
+		return "yellow"
+	default:
+		return "green"
+	}
+}
+
+// toPercent converts a numeric signal value to an int clamped to 0-100
+func toPercent(v interface{}) int {
+	var p float64
+	switch n := v.(type) {
+	case int:
+		p = float64(n)
+	case int8:
+		p = float64(n)
+	case int16:
+		p = float64(n)
+	case int32:
+		p = float64(n)
+	case int64:
+		p = float64(n)
+	case uint:
+		p = float64(n)
+	case uint8:
+		p = float64(n)
+	case uint16:
+		p = float64(n)
+	case uint32:
+		p = float64(n)
+	case uint64:
+		p = float64(n)
+	case float32:
+		p = float64(n)
+	case float64:
+		p = n
+	}
+
+	if p < 0 {
+		return 0
+	}
+	if p > 100 {
+		return 100
+	}
+	return int(p)
+}
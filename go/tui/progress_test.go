@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"basement/signals"
+	"testing"
+)
+
+func TestProgressBar(t *testing.T) {
+	value := signals.New(0)
+	bar := ProgressBar(value, 10, WithProgressRunes('#', '-'))
+
+	tests := []struct {
+		value int
+		want  string
+	}{
+		{0, "----------"},
+		{50, "#####-----"},
+		{100, "##########"},
+		{-5, "----------"},
+		{150, "##########"},
+	}
+
+	for _, tt := range tests {
+		value.Set(tt.value)
+		if got := bar.Get(); got != tt.want {
+			t.Errorf("%d%%: expected %q, got %q", tt.value, tt.want, got)
+		}
+	}
+}
+
+func TestProgressBarOptions(t *testing.T) {
+	value := signals.New(50.0)
+
+	if got := ProgressBar(value, 4, WithPercent()).Get(); got != "██░░ 50%" {
+		t.Errorf("Expected percent suffix, got %q", got)
+	}
+	if got := ProgressBar(value, 0, WithPercent()).Get(); got != "50%" {
+		t.Errorf("Expected only the percentage at width 0, got %q", got)
+	}
+	if got := ProgressBar(value, 0).Get(); got != "" {
+		t.Errorf("Expected empty bar at width 0, got %q", got)
+	}
+
+	value.Set(100)
+	got := GradientProgressBar(value, 6, WithProgressRunes('=', ' ')).Get()
+	if want := "#red(==)#yellow(==)#green(==)"; got != want {
+		t.Errorf("Expected gradient %q, got %q", want, got)
+	}
+}
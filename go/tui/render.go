@@ -3,166 +3,484 @@ package tui
 import (
 	"basement/basement"
 	"basement/signals"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
-	"unicode/utf8"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Renderable represents a parsed template ready to be rendered
 type Renderable struct {
 	Root *basement.Node
 	Args []interface{}
+
+	// Err is set when the template's holes can't be filled, see ErrMixedHoles.
+	// The holes then render as their literal text.
+	Err error
 }
 
-// Template parses the template and binds arguments
+// Bind supplies values for named %{name} holes:
+//
+//	tui.Template("Count: %{count}", tui.Bind{"count": count})
+type Bind map[string]interface{}
+
+// ErrMixedHoles is the Renderable.Err of a template using both positional %v
+// and named %{name} holes
+var ErrMixedHoles = errors.New("tui: template mixes positional %v and named %{...} holes")
+
+// Template parses the template and binds arguments.
+// Holes are either positional (%v, filled from args in order) or named
+// (%{name}, filled from a single Bind argument). Mixing both sets Err to
+// ErrMixedHoles and leaves the holes as literal text.
+// A named hole missing from the Bind renders as its literal %{name}.
+//
+// The parsed AST is cached by template string, so a view function that is
+// re-run on every update only pays for parsing once. Build dynamic text with
+// holes rather than fmt.Sprintf so each distinct string isn't cached. The
+// returned Root is shared between calls and must not be modified.
 func Template(template string, args ...interface{}) Renderable {
+	t := parseTemplate(template)
+	if len(t.names) > 0 {
+		args = bindNamed(t.names, args)
+	}
+
+	return Renderable{
+		Root: t.root,
+		Args: args,
+		Err:  t.err,
+	}
+}
+
+// parsedTemplate is a template's AST with its HoleIDs assigned
+type parsedTemplate struct {
+	root  *basement.Node
+	names []string // Named holes, indexed by HoleID
+	err   error
+}
+
+// templateCache maps a raw template string to its *parsedTemplate
+var templateCache sync.Map
+
+// parseTemplate returns the cached parse of template, parsing it on first use
+func parseTemplate(template string) *parsedTemplate {
+	if t, ok := templateCache.Load(template); ok {
+		return t.(*parsedTemplate)
+	}
+
 	root := basement.ParseAST(template)
 
 	// Assign HoleIDs
 	holeCount := 0
-	assignHoles(root, &holeCount)
+	var named []*basement.Node
+	assignHoles(root, &holeCount, &named)
+
+	t := &parsedTemplate{root: root}
+	if len(named) > 0 {
+		if holeCount > 0 {
+			t.err = fmt.Errorf("%w: %q", ErrMixedHoles, template)
+			literalHoles(root)
+			named = nil
+		}
+		for i, hole := range named {
+			hole.HoleID = i
+			t.names = append(t.names, hole.HoleName)
+		}
+	}
 
-	return Renderable{
-		Root: root,
-		Args: args,
+	actual, _ := templateCache.LoadOrStore(template, t)
+	return actual.(*parsedTemplate)
+}
+
+func assignHoles(n *basement.Node, count *int, named *[]*basement.Node) {
+	if n.Type == basement.NodeHole {
+		if n.HoleName != "" {
+			*named = append(*named, n)
+		} else {
+			n.HoleID = *count
+			*count++
+		}
+	}
+	for _, child := range n.Children {
+		assignHoles(child, count, named)
 	}
 }
 
-func assignHoles(n *basement.Node, count *int) {
+// literalHoles turns the holes under n back into the text they were written as
+func literalHoles(n *basement.Node) {
 	if n.Type == basement.NodeHole {
-		n.HoleID = *count
-		*count++
+		n.Type, n.Content = basement.NodeText, "%v"
+		if n.HoleName != "" {
+			n.Content = "%{" + n.HoleName + "}"
+		}
 	}
 	for _, child := range n.Children {
-		assignHoles(child, count)
+		literalHoles(child)
+	}
+}
+
+// bindNamed resolves named holes against the Bind in args, returning the
+// positional argument list the holes index into.
+func bindNamed(names []string, args []interface{}) []interface{} {
+	var bind Bind
+	if len(args) == 1 {
+		bind, _ = args[0].(Bind)
+	}
+
+	resolved := make([]interface{}, len(names))
+	for i, name := range names {
+		if val, ok := bind[name]; ok {
+			resolved[i] = val
+		} else {
+			resolved[i] = "%{" + name + "}"
+		}
 	}
+	return resolved
+}
+
+// RenderOptions configures RenderWithOptions
+type RenderOptions struct {
+	// MaxFPS caps how often the screen is redrawn. Updates arriving within one
+	// frame interval are coalesced into a single draw. 0 draws on every update.
+	MaxFPS int
+
+	// RuleLooks sets how rules are drawn by marker for this view only.
+	// Markers it leaves out are drawn as set with SetRuleLook.
+	RuleLooks map[basement.RuleStyle]RuleLook
 }
 
 // Render mounts the renderable to the screen
 func Render(screen *Screen, fn func() Renderable) {
-	// Create an effect for the rendering
-	signals.CreateEffect(func() {
+	RenderWithOptions(screen, fn, RenderOptions{})
+}
+
+// RenderWithOptions mounts the renderable to the screen with the given options
+func RenderWithOptions(screen *Screen, fn func() Renderable, opts RenderOptions) {
+	var content contentKey // Last content drawn, of rows rows
+	var rows int
+	draw := func() {
+		defer screen.recoverRender()
+
 		// Execute the view function inside the effect.
 		r := fn()
 
+		// Reading the scroll and size signals here makes scrolling and
+		// resizing redraw (and re-layout) the screen
+		scrollX, scrollY := screen.ScrollX.Get(), screen.ScrollY.Get()
+		width, height := screen.width.Get(), screen.height.Get()
+
+		// The whole content is only walked when it changed, not on every
+		// scroll, and scrolling stays within it if it shrank
+		key := contentKey{r.Root, resolveArgs(r.Args), width, height}
+		if !key.same(content) {
+			content, rows = key, contentRows(r, width, height)
+		}
+		clamped := clampScroll(scrollY, height, rows)
+
 		// Use Frame to lock once for the entire render cycle
 		screen.Frame(func() {
 			// Render the tree to the Back buffer
 			// Note: renderNode will access signal values via GetValue(),
 			// which registers this effect as a subscriber.
-			// Pass ScrollY as negative offset to y
-			renderNode(screen, r.Root, r.Args, 0, -screen.ScrollY)
+			// Pass the scroll position as a negative offset
+			screen.originX, screen.ruleLooks = scrollX, opts.RuleLooks
+			renderNode(screen, r.Root, r.Args, -scrollX, -clamped)
+			screen.originX, screen.ruleLooks = 0, nil
+			screen.drawToastsUnlocked()
 		})
+		// Outside Frame: effects reading them may draw too
+		screen.contentHeight.Set(rows)
+		screen.ScrollY.Set(clamped)
+	}
+
+	// Create an effect for the rendering, run after other effects reacting
+	// to the same change so it draws the state they leave
+	if opts.MaxFPS <= 0 {
+		signals.CreateEffectWithPriority(draw, renderPriority)
+		return
+	}
+	signals.CreateScheduledEffect(draw, newFrameScheduler(time.Second/time.Duration(opts.MaxFPS)))
+}
+
+// contentKey is what a view drew, to tell a change of content from a scroll
+type contentKey struct {
+	root          *basement.Node
+	args          []interface{} // Resolved, see resolveArgs
+	width, height int
+}
+
+// same reports whether k draws the same content as o. A change inside a
+// layout keeps its pointer, so layouts must also measure as they did.
+func (k contentKey) same(o contentKey) bool {
+	if k.root != o.root || k.width != o.width || k.height != o.height || len(k.args) != len(o.args) {
+		return false
+	}
+	for i, v := range k.args {
+		if !equalValues(v, o.args[i]) {
+			return false
+		}
+		if node, ok := v.(*LayoutNode); ok && !node.sameSize() {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveArgs returns the values of args, reading signals
+func resolveArgs(args []interface{}) []interface{} {
+	vals := make([]interface{}, len(args))
+	for i, arg := range args {
+		vals[i] = resolveValue(arg)
+	}
+	return vals
+}
+
+// equalValues compares a and b with ==, reporting false for values that
+// can't be compared
+func equalValues(a, b interface{}) (equal bool) {
+	defer func() {
+		if recover() != nil {
+			equal = false
+		}
+	}()
+	return a == b
+}
+
+// contentRows returns how many rows r takes up drawn on a width x height
+// screen, including the rows below it. Layouts fill at most the screen.
+func contentRows(r Renderable, width, height int) int {
+	_, y := drawHeadless(r, width, height)
+	return y
+}
+
+// renderPriority orders render effects after effects of the default priority
+const renderPriority = -1
+
+// recoverRender handles a panic in a render effect, see Screen.OnError
+func (s *Screen) recoverRender() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if s.OnError == nil {
+		s.Close()
+		panic(r)
+	}
+
+	err, ok := r.(error)
+	if !ok {
+		err = fmt.Errorf("%v", r)
+	}
+	s.Frame(func() {
+		s.drawTextUnlocked(0, 0, "render error: "+err.Error(), basement.Style{Bold: true, Color: basement.GetColorCode("red")})
 	})
+	s.OnError(err)
+}
+
+// newFrameScheduler returns an effect scheduler that runs at most one re-run
+// per interval. The first update after an idle period arms a timer for the next
+// frame slot; updates arriving before it fires share that single draw.
+// Nothing runs while there are no updates.
+func newFrameScheduler(interval time.Duration) func(run func()) {
+	var (
+		mu        sync.Mutex
+		pending   bool
+		lastFrame time.Time
+		runMu     sync.Mutex // Serialises draws
+	)
+
+	return func(run func()) {
+		mu.Lock()
+		defer mu.Unlock()
+		if pending {
+			return
+		}
+		pending = true
+
+		delay := interval - time.Since(lastFrame)
+		if delay < 0 {
+			delay = 0
+		}
+		afterFunc(delay, func() {
+			mu.Lock()
+			pending = false
+			lastFrame = time.Now()
+			mu.Unlock()
+
+			runMu.Lock()
+			defer runMu.Unlock()
+			run()
+		})
+	}
+}
+
+// RenderToString renders r into an off-screen width x height buffer and returns
+// it as plain text, one line per row with trailing spaces trimmed.
+// A height of 0 or less makes the buffer exactly as tall as the content, up
+// to maxHeadlessHeight rows for layouts that stretch to fill it.
+// Useful for snapshot tests of templates and layouts.
+func RenderToString(r Renderable, width, height int) string {
+	return renderHeadless(r, width, height).Back.String()
+}
+
+// RenderToANSI is like RenderToString but keeps styles as ANSI escape codes,
+// e.g. to write a rich template to a file or pipe it to a pager.
+func RenderToANSI(r Renderable, width, height int) string {
+	var sb strings.Builder
+	s := renderHeadless(r, width, height)
+	s.out.Reset(&sb)
+	s.writeANSI(s.Back)
+	s.out.Flush()
+	return sb.String()
+}
+
+// maxHeadlessHeight caps the buffer renderHeadless grows to fit the content.
+// Content that stretches to fill the space it is given, like a layout with
+// Flex rows, would otherwise keep outgrowing every buffer.
+const maxHeadlessHeight = 1024
+
+// renderHeadless draws r into the back buffer of a screen with no terminal.
+// Without a height, it retries with a taller buffer until the content fits,
+// then drops the unused rows. Text below the buffer still counts toward the
+// height drawn, so it fits on the next try; layouts only see the rows left,
+// so content still reaching the bottom at maxHeadlessHeight is cut there.
+func renderHeadless(r Renderable, width, height int) *Screen {
+	if height > 0 {
+		s, _ := drawHeadless(r, width, height)
+		return s
+	}
+	for h := 64; ; {
+		s, y := drawHeadless(r, width, h)
+		if y < h {
+			s.Back.Height = y
+			s.Back.Cells = s.Back.Cells[:y*width]
+			return s
+		}
+		if h >= maxHeadlessHeight {
+			return s
+		}
+		if h *= 2; h <= y {
+			h = y + 1
+		}
+	}
+}
+
+// drawHeadless draws r into a width x height screen with no terminal and
+// returns it with the full height of the content
+func drawHeadless(r Renderable, width, height int) (*Screen, int) {
+	s := newHeadlessScreen(width, height, io.Discard)
+	s.walkAll = true
+	s.clearBackBuf()
+	_, y := renderNode(s, r.Root, r.Args, 0, 0)
+	return s, y
 }
 
 // renderNode draws the node to the screen. Returns the new X, Y position.
 func renderNode(s *Screen, n *basement.Node, args []interface{}, x, y int) (int, int) {
-	// Early exit if node is completely below the viewport
-	// Note: This assumes nodes render downwards.
-	// If y >= Height, we can skip.
-	// But we need to be careful about returning the correct Y advancement for layout.
-	// Since we don't have a layout engine here (except for LayoutNode),
-	// skipping might be tricky if we need to calculate height.
-	// However, for simple flow, if we are off screen, we can just return y + estimated height?
-	// Or just let it run but DrawText will clip.
-	// The optimization requested is "Early exit when y >= Height".
-
-	if y >= s.Back.Height {
-		// We still need to traverse to find the correct next Y?
-		// If we return y, the parent loop continues.
-		// If we return y + height, the parent loop continues.
-		// If we stop traversing, we might miss side effects? (Unlikely in render)
-		// But we might miss calculating the total height if we needed it.
-		// Since we don't use the returned Y for anything other than placing the next sibling,
-		// and the next sibling will also be off-screen, it's safe to return y.
-		// Wait, if we return y, the next sibling draws at y.
-		// If we return y+1, next sibling draws at y+1.
-		// It doesn't matter much if they are all off screen.
+	return renderStyled(s, n, args, x, y, basement.Style{})
+}
+
+// renderStyled draws n with the style inherited from its ancestors merged
+// under its own. Passing the style down avoids copying nodes to restyle them.
+// Nodes below the screen are skipped unless s.walkAll is set, which makes the
+// returned Y of the root the full height of the content.
+func renderStyled(s *Screen, n *basement.Node, args []interface{}, x, y int, inherited basement.Style) (int, int) {
+	if y >= s.Back.Height && !s.walkAll {
 		return x, y
 	}
+	style := mergeStyles(inherited, n.Style)
 
 	switch n.Type {
 	case basement.NodeRoot:
 		curY := y
 		for _, child := range n.Children {
-			_, newY := renderNode(s, child, args, x, curY)
+			_, newY := renderStyled(s, child, args, x, curY, style)
 			curY = newY // Don't add extra line here, blocks handle it
 		}
 		return x, curY
 
 	case basement.NodeBlock, basement.NodeHeader:
-		// Apply block style
-		curX := x
-		maxY := y
-		for _, child := range n.Children {
-			// Inherit style from block
-			mergedStyle := mergeStyles(n.Style, child.Style)
-
-			// Shallow copy to avoid mutating AST
-			tempChild := *child
-			tempChild.Style = mergedStyle
-
-			newX, newY := renderNode(s, &tempChild, args, curX, y)
-			curX = newX
-			if newY > maxY {
-				maxY = newY
-			}
-		}
-		// Normal inline content stays on one line (maxY == y), so advance by 1.
-		// But if a child (e.g. LayoutNode via %v) consumed multiple lines, respect that.
-		if maxY <= y {
-			maxY = y + 1
-		}
-		return x, maxY
+		// Children inherit the block style
+		return x, renderInline(s, n.Children, args, x, y, style)
 
 	case basement.NodeHR:
-		// Draw a horizontal line
+		// Draw a horizontal line from x to the right edge of the screen (or
+		// of the box being drawn into), centered if capped
 		if y >= 0 && y < s.Back.Height {
-			for i := 0; i < s.Back.Width; i++ {
-				s.Back.Set(i, y, '─', basement.Style{Dim: true})
+			right := s.Back.Width
+			if n := len(s.Back.clips); n > 0 {
+				if c := s.Back.clips[n-1]; c.X+c.W < right {
+					right = c.X + c.W
+				}
+			}
+			w := right - x
+			start := x
+			if max := int(atomic.LoadInt32(&ruleWidth)); max > 0 && max < w {
+				start += (w - max) / 2
+				w = max
+			}
+			look := s.ruleLook(n.Rule)
+			for i := 0; i < w; i++ {
+				s.Back.Set(start+i, y, look.Char, look.Style)
 			}
 		}
 		return x, y + 1
 
 	case basement.NodeQuote:
-		// Draw quote bar
+		// Draw one quote bar per nesting level
+		depth := quoteDepth(n)
 		if y >= 0 && y < s.Back.Height {
-			s.Back.Set(x, y, '│', basement.Style{Dim: true})
-		}
-		curX := x + 2 // Indent
-		for _, child := range n.Children {
-			newX, _ := renderNode(s, child, args, curX, y)
-			curX = newX
+			bar, barStyle := QuoteStyle()
+			for i := 0; i < depth; i++ {
+				s.Back.Set(x+i*2, y, bar, barStyle)
+			}
 		}
-		return x, y + 1
+		return x, renderInline(s, n.Children, args, x+depth*2, y, style) // Indent
 
 	case basement.NodeList:
 		curY := y
 		for _, child := range n.Children {
-			_, newY := renderNode(s, child, args, x, curY)
+			_, newY := renderStyled(s, child, args, x, curY, style)
 			curY = newY
 		}
 		return x, curY
 
 	case basement.NodeListItem:
-		// Draw bullet
-		if y >= 0 && y < s.Back.Height {
-			s.Back.Set(x, y, '•', basement.Style{})
+		// Draw bullet, or a checkbox for a task
+		bullet := '•'
+		if n.Checked {
+			bullet = '☑'
+		} else if n.Task {
+			bullet = '☐'
 		}
-		curX := x + 2
-		for _, child := range n.Children {
-			newX, _ := renderNode(s, child, args, curX, y)
-			curX = newX
+		if y >= 0 && y < s.Back.Height {
+			s.Back.Set(x, y, bullet, basement.Style{})
 		}
-		return x, y + 1
+		return x, renderInline(s, n.Children, args, x+2, y, style)
 
 	case basement.NodeCodeBlock:
 		// Use Highlighter
-		spans := Highlight(n.Content, n.Lang)
+		code := expandTabs(n.Content, 0)
+		spans := Highlight(code, n.Lang)
+		marked := parseLineSet(n.Attrs["highlight"])
+
+		// Background panel as wide as the longest line plus the padding
+		bg := CodeBackground()
+		if bg != "" {
+			lines := strings.Split(strings.TrimSuffix(code, "\n"), "\n")
+			w := 0
+			for _, l := range lines {
+				if lw := stringWidth(l); lw > w {
+					w = lw
+				}
+			}
+			s.Back.FillRect(x, y, w+2*codePadding, len(lines), ' ', basement.Style{BgColor: bg})
+		}
 
 		curY := y
-		curX := x
+		curX := x + codePadding
 
 		for _, span := range spans {
 			// Handle newlines in span text
@@ -170,15 +488,22 @@ func renderNode(s *Screen, n *basement.Node, args []interface{}, x, y int) (int,
 			for i, part := range parts {
 				if i > 0 {
 					curY++
-					curX = x
+					curX = x + codePadding
 				}
 				if part == "" { continue }
 
 				if curY >= 0 && curY < s.Back.Height {
+					st := span.Style
+					if marked.has(curY - y + 1) {
+						st = emphasize(st)
+					}
+					if st.BgColor == "" {
+						st.BgColor = bg
+					}
 					// Use unlocked version since we are inside Frame()
-					s.drawTextUnlocked(curX, curY, part, span.Style)
+					s.drawTextUnlocked(curX, curY, part, st)
 				}
-				curX += utf8.RuneCountInString(part)
+				curX += stringWidth(part)
 			}
 		}
 		return x, curY + 1
@@ -189,27 +514,30 @@ func renderNode(s *Screen, n *basement.Node, args []interface{}, x, y int) (int,
 		if n.Content == "" {
 			return x, y + 1 // Treat as newline
 		}
+		text := expandTabs(n.Content, x+s.originX)
 		if y >= 0 && y < s.Back.Height {
 			// Use unlocked version since we are inside Frame()
-			s.drawTextUnlocked(x, y, n.Content, n.Style)
+			s.drawTextUnlocked(x, y, text, style)
 		}
-		return x + utf8.RuneCountInString(n.Content), y
+		return x + stringWidth(text), y
 
-	case basement.NodeStyle:
-		curX := x
-		for _, child := range n.Children {
-			mergedStyle := mergeStyles(n.Style, child.Style)
-
-			tempChild := *child // Shallow copy
-			tempChild.Style = mergedStyle
+	case basement.NodeImage:
+		// Terminals can't show the image itself, so draw a placeholder
+		text := imageText(n)
+		if y >= 0 && y < s.Back.Height {
+			s.drawTextUnlocked(x, y, text, mergeStyles(style, basement.Style{Dim: true}))
+		}
+		return x + stringWidth(text), y
 
-			newX, _ := renderNode(s, &tempChild, args, curX, y)
-			curX = newX
+	case basement.NodeStyle, basement.NodeLink, basement.NodeAbbr:
+		curX, curY := x, y
+		for _, child := range n.Children {
+			curX, curY = renderStyled(s, child, args, curX, curY, style)
 		}
-		return curX, y
+		return curX, curY
 
 	case basement.NodeHole:
-		if n.HoleID < len(args) {
+		if n.HoleID >= 0 && n.HoleID < len(args) {
 			val := args[n.HoleID]
 
 			// Resolve signal if present
@@ -226,37 +554,180 @@ func renderNode(s *Screen, n *basement.Node, args []interface{}, x, y int) (int,
 				return x, y + h
 			}
 
+			// Pre-formatted ANSI: styles come from its escapes
+			if raw, ok := val.(Raw); ok {
+				return s.drawRawUnlocked(x, y, raw, style)
+			}
+
 			str := fmt.Sprintf("%v", val)
 
 			if containsMarkup(str) {
-				dynamicRoot := basement.ParseAST(str)
-				curX := x
+				dynamicRoot := holeFragments.parse(str)
+				curX, curY := x, y
+				first := true
 				for _, child := range dynamicRoot.Children {
-					if child.Type == basement.NodeBlock {
-						for _, inlineChild := range child.Children {
-							mergedStyle := mergeStyles(n.Style, inlineChild.Style)
-							tempChild := *inlineChild
-							tempChild.Style = mergedStyle
-							newX, _ := renderNode(s, &tempChild, nil, curX, y)
-							curX = newX
-						}
+					if child.Type != basement.NodeBlock {
+						continue
+					}
+					// Each line of the value is a block of its own
+					if !first {
+						curX, curY = x, curY+1
+					}
+					first = false
+					for _, inlineChild := range child.Children {
+						curX, curY = renderStyled(s, inlineChild, nil, curX, curY, style)
 					}
 				}
-				return curX, y
+				return curX, curY
 			} else {
-				if y >= 0 && y < s.Back.Height {
-					// Use unlocked version since we are inside Frame()
-					s.drawTextUnlocked(x, y, str, n.Style)
+				str = expandTabs(str, x+s.originX)
+				// Use unlocked version since we are inside Frame().
+				// Lines after the first start back at x; the next node
+				// continues after the last one.
+				s.drawTextUnlocked(x, y, str, style)
+				if i := strings.LastIndexByte(str, '\n'); i >= 0 {
+					return x + stringWidth(str[i+1:]), y + strings.Count(str, "\n")
 				}
-				return x + utf8.RuneCountInString(str), y
+				return x + stringWidth(str), y
 			}
 		}
 	}
 	return x, y
 }
 
+// codePadding is the number of blank columns either side of code block lines
+const codePadding = 1
+
+// codeBackground is the background escape code of code blocks
+var (
+	codeBgMu sync.RWMutex
+	codeBg   = "\x1b[48;5;236m" // Dark grey
+)
+
+// SetCodeBackground sets the background of code blocks to an ANSI escape
+// code, e.g. "\x1b[48;5;17m" for dark blue. "" turns the background off.
+func SetCodeBackground(ansiCode string) {
+	codeBgMu.Lock()
+	defer codeBgMu.Unlock()
+	codeBg = ansiCode
+}
+
+// CodeBackground returns the background escape code of code blocks
+func CodeBackground() string {
+	codeBgMu.RLock()
+	defer codeBgMu.RUnlock()
+	return codeBg
+}
+
+// Quote bar look, see SetQuoteStyle
+var (
+	quoteMu    sync.RWMutex
+	quoteBar   = '│'
+	quoteStyle = basement.Style{Dim: true}
+)
+
+// SetQuoteStyle changes the bar drawn at the left of a blockquote, once per
+// nesting level, e.g. SetQuoteStyle('┃', basement.Style{Color: "\x1b[32m"}).
+// A zero char keeps the default '│'.
+func SetQuoteStyle(char rune, style basement.Style) {
+	if char == 0 {
+		char = '│'
+	}
+	quoteMu.Lock()
+	defer quoteMu.Unlock()
+	quoteBar, quoteStyle = char, style
+}
+
+// QuoteStyle returns the blockquote bar character and its style
+func QuoteStyle() (rune, basement.Style) {
+	quoteMu.RLock()
+	defer quoteMu.RUnlock()
+	return quoteBar, quoteStyle
+}
+
+// RuleLook is how a horizontal rule is drawn: one character repeated in a style
+type RuleLook struct {
+	Char  rune
+	Style basement.Style
+}
+
+// ruleLooks maps each rule marker (---, ***, ___) to its look
+var (
+	ruleMu    sync.RWMutex
+	ruleLooks = map[basement.RuleStyle]RuleLook{
+		basement.RuleLight:  {'─', basement.Style{Dim: true}},
+		basement.RuleHeavy:  {'━', basement.Style{Dim: true}},
+		basement.RuleDouble: {'═', basement.Style{Dim: true}},
+	}
+)
+
+// SetRuleLook changes how rules written with the given marker are drawn,
+// e.g. SetRuleLook(basement.RuleLight, RuleLook{Char: '•'})
+func SetRuleLook(kind basement.RuleStyle, look RuleLook) {
+	ruleMu.Lock()
+	defer ruleMu.Unlock()
+	ruleLooks[kind] = look
+}
+
+// RuleLookFor returns how rules written with the given marker are drawn
+func RuleLookFor(kind basement.RuleStyle) RuleLook {
+	ruleMu.RLock()
+	defer ruleMu.RUnlock()
+	return ruleLooks[kind]
+}
+
+// ruleLook returns how s draws rules written with the given marker: the
+// drawing Render's look if it sets one, the global one otherwise
+func (s *Screen) ruleLook(kind basement.RuleStyle) RuleLook {
+	if look, ok := s.ruleLooks[kind]; ok {
+		return look
+	}
+	return RuleLookFor(kind)
+}
+
+// ruleWidth caps the width of horizontal rules; 0 means no cap
+var ruleWidth int32
+
+// SetRuleWidth caps horizontal rules at width columns, centered in the space
+// they would otherwise fill. 0 (the default) lets them span the full width.
+func SetRuleWidth(width int) {
+	atomic.StoreInt32(&ruleWidth, int32(width))
+}
+
+// renderInline draws inline nodes left to right from (x, y) and returns the
+// row below the last one they used. A multi-line hole leaves the next node on
+// its last line; a layout hole leaves it at x on the row below the layout.
+func renderInline(s *Screen, children []*basement.Node, args []interface{}, x, y int, style basement.Style) int {
+	curX, curY := x, y
+	for _, child := range children {
+		curX, curY = renderStyled(s, child, args, curX, curY, style)
+	}
+	if curY > y && curX == x {
+		// Already at the start of a fresh row
+		return curY
+	}
+	return curY + 1
+}
+
+// imageText is the placeholder drawn in place of an image
+func imageText(n *basement.Node) string {
+	if n.Content == "" {
+		return "[image]"
+	}
+	return "[image: " + n.Content + "]"
+}
+
+// quoteDepth returns the nesting level of a quote node (at least 1)
+func quoteDepth(n *basement.Node) int {
+	if n.Depth < 1 {
+		return 1
+	}
+	return n.Depth
+}
+
 func containsMarkup(s string) bool {
-	for _, char := range []string{"**", "__", "#", "!"} {
+	// "~" also covers "~~" (strikethrough), "!" covers "!!" (reverse)
+	for _, char := range []string{"**", "__", "~", "^", "`", "#", "!", "--", "::", "??"} {
 		if strings.Contains(s, char) {
 			return true
 		}
@@ -283,6 +754,7 @@ func mergeStyles(parent, child basement.Style) basement.Style {
 		Strike:    parent.Strike || child.Strike,
 		Reverse:   parent.Reverse || child.Reverse,
 		Blink:     parent.Blink || child.Blink,
+		Hidden:    parent.Hidden || child.Hidden,
 		Color:     color,
 		BgColor:   bgColor,
 	}
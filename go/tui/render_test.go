@@ -0,0 +1,612 @@
+package tui
+
+import (
+	"basement/basement"
+	"basement/signals"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderToStringGolden(t *testing.T) {
+	r := Template("# Title\n**Count**: %v\n- one\n- two\n> quoted", 42)
+	got := RenderToString(r, 20, 6)
+
+	want, err := os.ReadFile("testdata/render_template.golden")
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("Output mismatch.\nExpected:\n%s\nActual:\n%s", want, got)
+	}
+}
+
+func TestRenderToANSI(t *testing.T) {
+	got := RenderToANSI(Template("**hi**"), 10, 1)
+	if !strings.HasPrefix(got, "\x1b[1mhi\x1b[0m") {
+		t.Errorf("Expected bold escape around text, got %q", got)
+	}
+}
+
+func TestRenderToANSIWideRunes(t *testing.T) {
+	got := RenderToANSI(Template("日本 ok"), 10, 1)
+	if got != "日本 ok\n" {
+		t.Errorf("Expected each wide rune written once, got %q", got)
+	}
+}
+
+func TestRenderToANSIFitsContent(t *testing.T) {
+	r := Template("# Title\n- one\n- two")
+	got := RenderToANSI(r, 20, 0)
+
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected one line per block, got %q", got)
+	}
+	if !strings.Contains(lines[0], "\x1b[") || !strings.Contains(lines[0], "Title") {
+		t.Errorf("Expected a styled header, got %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "• one") || !strings.Contains(lines[2], "• two") {
+		t.Errorf("Expected list items, got %q", lines[1:])
+	}
+
+	// Content taller than the first guess still fits
+	long := strings.Repeat("x\n", 99) + "end"
+	if got := RenderToString(Template(long), 5, 0); got != strings.Repeat("x\n", 99)+"end\n" {
+		t.Errorf("Expected all 100 lines, got %d", strings.Count(got, "\n"))
+	}
+
+	// Text past the cap still fits in one more try
+	long = strings.Repeat("x\n", maxHeadlessHeight+99) + "end"
+	if got := ContentHeight(Template(long), 5); got != maxHeadlessHeight+100 {
+		t.Errorf("Expected %d lines, got %d", maxHeadlessHeight+100, got)
+	}
+}
+
+func TestRenderToStringFlexLayout(t *testing.T) {
+	// A Flex row fills any buffer it is given, so sizing stops at the cap
+	layout := Col(Box("top", true, 0), Box("body", true, 0).WithHeight(Flex(1)))
+	got := RenderToString(Template("%v", layout), 10, 0)
+	if n := strings.Count(got, "\n"); n != maxHeadlessHeight {
+		t.Errorf("Expected %d rows, got %d", maxHeadlessHeight, n)
+	}
+	if !strings.HasPrefix(got, "┌───┐\n│top│\n└───┘\n┌────┐\n│body│\n") {
+		t.Errorf("Expected both boxes drawn, got %q", got[:60])
+	}
+	if got := ContentHeight(Template("%v\nafter", layout), 10); got != maxHeadlessHeight {
+		t.Errorf("Expected text after the layout cut at the cap, got %d", got)
+	}
+}
+
+func TestRuleWidth(t *testing.T) {
+	if got := RenderToString(Template("***"), 6, 1); got != "━━━━━━\n" {
+		t.Errorf("Expected a full-width heavy rule, got %q", got)
+	}
+
+	SetRuleWidth(4)
+	defer SetRuleWidth(0)
+	if got := RenderToString(Template("___"), 10, 1); got != "   ════\n" {
+		t.Errorf("Expected a centered 4-column double rule, got %q", got)
+	}
+}
+
+func TestCodeBlockHighlightedLines(t *testing.T) {
+	s := renderHeadless(Template("```text {highlight=2-3}\none\ntwo\nthree\nfour\n```"), 10, 4)
+	for line, want := range []bool{false, true, true, false} {
+		st := s.Back.Get(1, line).Style
+		if st.Bold != want || want && st.Dim {
+			t.Errorf("Line %d: expected highlighted %v, got %+v", line+1, want, st)
+		}
+	}
+}
+
+func TestCodeBlockExpandsTabs(t *testing.T) {
+	// Padded by one column and filled to the widest line on either side
+	got := RenderToString(Template("```\nif x {\n\ty\n}\n```"), 10, 3)
+	if want := " if x { \n     y  \n }      \n"; got != want {
+		t.Errorf("Expected the tab expanded to 4 spaces, got %q", got)
+	}
+}
+
+func TestCodeBlockBackground(t *testing.T) {
+	defer SetCodeBackground(CodeBackground())
+	SetCodeBackground("\x1b[44m")
+
+	s := renderHeadless(Template("```\nab\nlonger\n```"), 12, 2)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 8; x++ {
+			if got := s.Back.Get(x, y).Style.BgColor; got != "\x1b[44m" {
+				t.Errorf("Cell (%d, %d): expected the code background, got %q", x, y, got)
+			}
+		}
+		if got := s.Back.Get(8, y).Style.BgColor; got != "" {
+			t.Errorf("Row %d: expected the background to stop after the padding, got %q", y, got)
+		}
+	}
+	if got := s.Back.String(); got != " ab     \n longer \n" {
+		t.Errorf("Expected padded code lines, got %q", got)
+	}
+
+	SetCodeBackground("")
+	s = renderHeadless(Template("```\nab\n```"), 12, 1)
+	if got := s.Back.Get(1, 0).Style.BgColor; got != "" {
+		t.Errorf("Expected no background once turned off, got %q", got)
+	}
+}
+
+func TestCRLFDrawsNoCarriageReturns(t *testing.T) {
+	s := renderHeadless(Template("# Title\r\ntext\r\n---\r\n"), 10, 3)
+	for i, c := range s.Back.Cells {
+		if c.Char == '\r' {
+			t.Fatalf("Expected no carriage return cells, found one at %d in %q", i, s.Back.String())
+		}
+	}
+	if got := s.Back.Get(0, 2).Char; got != '─' {
+		t.Errorf("Expected the rule recognized on its CRLF line, got %q", got)
+	}
+}
+
+func TestRuleLookAndBounds(t *testing.T) {
+	SetRuleLook(basement.RuleLight, RuleLook{Char: '•', Style: basement.Style{Bold: true}})
+	defer SetRuleLook(basement.RuleLight, RuleLook{Char: '─', Style: basement.Style{Dim: true}})
+
+	// Inside a bordered box the rule stops at the box's content edge
+	box := Box(Text("abcd\n---"), true, 0)
+	s := renderHeadless(Template("%v", Row(Spacer().WithWidth(Fixed(2)), box)), 10, 4)
+	if got := s.Back.String(); got != "  ┌────┐\n  │abcd│\n  │••••│\n  └────┘\n" {
+		t.Errorf("Expected the rule inside the box only, got %q", got)
+	}
+	if !s.Back.Get(3, 2).Style.Bold {
+		t.Errorf("Expected the custom rule style, got %+v", s.Back.Get(3, 2).Style)
+	}
+
+	// A capped rule is centered in the box, not on the screen
+	SetRuleWidth(2)
+	defer SetRuleWidth(0)
+	s = renderHeadless(Template("%v", Row(Spacer().WithWidth(Fixed(2)), box)), 10, 4)
+	if got := s.Back.String(); !strings.Contains(got, "│ •• │") {
+		t.Errorf("Expected the capped rule centered in the box, got %q", got)
+	}
+}
+
+func TestRenderOptionsRuleLooks(t *testing.T) {
+	view := func() Renderable { return Template("---\n***") }
+	dots := newTestScreen(3, 2)
+	RenderWithOptions(dots, view, RenderOptions{RuleLooks: map[basement.RuleStyle]RuleLook{basement.RuleLight: {Char: '•'}}})
+	plain := newTestScreen(3, 2)
+	Render(plain, view)
+
+	// Each view keeps its own look; markers it doesn't set use the global one
+	if got := dots.Front.String(); got != "•••\n━━━\n" {
+		t.Errorf("Expected the view's rule look, got %q", got)
+	}
+	if got := plain.Front.String(); got != "───\n━━━\n" {
+		t.Errorf("Expected the global rule looks, got %q", got)
+	}
+}
+
+func TestQuoteStyle(t *testing.T) {
+	green := basement.Style{Color: "\x1b[32m"}
+	SetQuoteStyle('┃', green)
+	defer SetQuoteStyle('│', basement.Style{Dim: true})
+
+	s := renderHeadless(Template("> one\n>> two"), 10, 2)
+	if got := s.Back.String(); got != "┃ one\n┃ ┃ two\n" {
+		t.Errorf("Expected a bar per nesting level, got %q", got)
+	}
+	for _, at := range [][2]int{{0, 0}, {0, 1}, {2, 1}} {
+		if c := s.Back.Get(at[0], at[1]); c.Char != '┃' || c.Style != green {
+			t.Errorf("Expected a green bar at %v, got %+v", at, c)
+		}
+	}
+}
+
+func TestTaskListCheckboxes(t *testing.T) {
+	got := RenderToString(Template("- [ ] todo\n- [x] done\n- plain"), 10, 0)
+	if want := "☐ todo\n☑ done\n• plain\n"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestMultiLineHole(t *testing.T) {
+	got := RenderToString(Template("x %v!\nnext", "one\ntwo"), 10, 4)
+	want := "x one\n  two!\nnext\n\n"
+	if got != want {
+		t.Errorf("Expected the text after the hole on its last line and the next block below, got %q", got)
+	}
+
+	// Holes holding markup span lines the same way
+	got = RenderToString(Template("%v!\nnext", "**a**\nb"), 10, 3)
+	if want := "a\nb!\nnext\n"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestStrikeThroughHole(t *testing.T) {
+	s := newTestScreen(20, 1)
+	r := Template("%v", "~~gone~~")
+	renderNode(s, r.Root, r.Args, 0, 0)
+
+	for i, want := range "gone" {
+		cell := s.Back.Get(i, 0)
+		if cell.Char != want || !cell.Style.Strike {
+			t.Errorf("Cell %d: expected struck %q, got %q (strike=%v)", i, want, cell.Char, cell.Style.Strike)
+		}
+	}
+
+	var sb strings.Builder
+	s = newHeadlessScreen(20, 1, &sb)
+	s.supportsStrike = false
+	s.writeStyle(basement.Style{Strike: true})
+	s.out.Flush()
+	if strings.Contains(sb.String(), "\x1b[9m") {
+		t.Errorf("Expected no strike escape when unsupported, got %q", sb.String())
+	}
+}
+
+func TestNamedHoles(t *testing.T) {
+	count := signals.New(3)
+	r := Template("%{name}: %{count} (%{count})", Bind{"name": "Total", "count": count})
+
+	if got := RenderToString(r, 20, 1); got != "Total: 3 (3)\n" {
+		t.Errorf("Expected named holes to resolve, got %q", got)
+	}
+
+	// A name missing from the Bind is shown literally
+	r = Template("Hi %{who}", Bind{})
+	if got := RenderToString(r, 20, 1); got != "Hi %{who}\n" {
+		t.Errorf("Expected missing name to render literally, got %q", got)
+	}
+}
+
+func TestMixedHolesRenderLiterally(t *testing.T) {
+	r := Template("%v and %{name}", 1)
+	if !errors.Is(r.Err, ErrMixedHoles) {
+		t.Errorf("Expected ErrMixedHoles, got %v", r.Err)
+	}
+	if got := RenderToString(r, 20, 1); got != "%v and %{name}\n" {
+		t.Errorf("Expected the holes as literal text, got %q", got)
+	}
+	if r := Template("%v and %v", 1, 2); r.Err != nil {
+		t.Errorf("Expected no error for positional holes, got %v", r.Err)
+	}
+}
+
+func TestTemplateCacheKeepsHoles(t *testing.T) {
+	const tmpl = "**%v** of %v"
+	a := Template(tmpl, 1, 2)
+	b := Template(tmpl, "x", "y")
+	if a.Root != b.Root {
+		t.Errorf("Expected the parsed AST to be reused")
+	}
+	if got := RenderToString(b, 20, 1); got != "x of y\n" {
+		t.Errorf("Expected holes filled from the new args, got %q", got)
+	}
+	if got := RenderToString(a, 20, 1); got != "1 of 2\n" {
+		t.Errorf("Expected the earlier args to be unaffected, got %q", got)
+	}
+
+	n := Template("%{a}-%{b}", Bind{"b": 2, "a": 1})
+	if got := RenderToString(Template("%{a}-%{b}", Bind{"a": "p", "b": "q"}), 20, 1); got != "p-q\n" {
+		t.Errorf("Expected cached named holes to rebind, got %q", got)
+	}
+	if got := RenderToString(n, 20, 1); got != "1-2\n" {
+		t.Errorf("Expected named holes in order, got %q", got)
+	}
+}
+
+func BenchmarkTemplateCached(b *testing.B) {
+	doc := strings.Repeat("# Title\n\nSome **bold** and __underlined__ text, %v.\n\n* item\n* item\n", 20)
+	for i := 0; i < b.N; i++ {
+		Template(doc, i)
+	}
+}
+
+func BenchmarkTemplateUncached(b *testing.B) {
+	doc := strings.Repeat("# Title\n\nSome **bold** and __underlined__ text, %v.\n\n* item\n* item\n", 20)
+	for i := 0; i < b.N; i++ {
+		basement.ParseAST(doc)
+	}
+}
+
+func TestImagePlaceholder(t *testing.T) {
+	got := RenderToString(Template("![diagram](d.png) done"), 30, 1)
+	if want := "[image: diagram] done\n"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestRenderMaxFPSCoalesces(t *testing.T) {
+	// Frames fire when the test says so
+	var frames []func()
+	defer func(f func(time.Duration, func()) *time.Timer) { afterFunc = f }(afterFunc)
+	afterFunc = func(d time.Duration, f func()) *time.Timer {
+		if d > 50*time.Millisecond {
+			t.Errorf("Expected a frame within 1/20s, got a delay of %v", d)
+		}
+		frames = append(frames, f)
+		return nil
+	}
+
+	s := newTestScreen(20, 1)
+	count := signals.New(0)
+	draws := 0
+	RenderWithOptions(s, func() Renderable {
+		draws++
+		return Template("%v", count)
+	}, RenderOptions{MaxFPS: 20})
+
+	for i := 1; i <= 10; i++ {
+		count.Set(i)
+	}
+	if len(frames) != 1 || draws != 1 {
+		t.Fatalf("Expected the ten updates to wait for one frame, got %d frames and %d draws", len(frames), draws)
+	}
+
+	// One initial draw plus one coalesced draw for all ten updates
+	frames[0]()
+	if draws != 2 {
+		t.Errorf("Expected 2 draws, got %d", draws)
+	}
+	if got := s.Front.String(); got != "10\n" {
+		t.Errorf("Expected the last value on screen, got %q", got)
+	}
+
+	// The next update waits for a frame of its own
+	count.Set(11)
+	if len(frames) != 2 {
+		t.Fatalf("Expected a second frame, got %d", len(frames))
+	}
+	frames[1]()
+	if got := s.Front.String(); got != "11\n" || draws != 3 {
+		t.Errorf("Expected 11 drawn in a third draw, got %q after %d draws", got, draws)
+	}
+}
+
+func TestRenderPanicRestoresTerminal(t *testing.T) {
+	var out strings.Builder
+	s := newHeadlessScreen(20, 1, &out)
+	items := []string{"a", "b"}
+	idx := signals.New(0)
+	Render(s, func() Renderable { return Template("%v", items[idx.Get()]) })
+
+	var repanicked interface{}
+	func() {
+		defer func() { repanicked = recover() }()
+		idx.Set(5)
+	}()
+	if repanicked == nil {
+		t.Fatalf("Expected the panic to be re-raised without OnError")
+	}
+	if !strings.Contains(out.String(), "\x1b[?25h") {
+		t.Errorf("Expected the terminal restored before re-panicking, got %q", out.String())
+	}
+}
+
+func TestRenderPanicCallsOnError(t *testing.T) {
+	s := newTestScreen(40, 1)
+	var got error
+	s.OnError = func(err error) { got = err }
+
+	items := []string{"a", "b"}
+	idx := signals.New(0)
+	Render(s, func() Renderable { return Template("%v", items[idx.Get()]) })
+
+	idx.Set(5)
+	if got == nil || !strings.Contains(got.Error(), "index out of range") {
+		t.Fatalf("Expected OnError to receive the panic, got %v", got)
+	}
+	if !strings.HasPrefix(s.Front.String(), "render error: ") {
+		t.Errorf("Expected an error panel, got %q", s.Front.String())
+	}
+
+	// The view comes back once the state is valid again
+	idx.Set(1)
+	if got := s.Front.String(); got != "b\n" {
+		t.Errorf("Expected the view redrawn, got %q", got)
+	}
+}
+
+func TestRenderComputedPanicCallsOnError(t *testing.T) {
+	s := newTestScreen(40, 1)
+	var got error
+	s.OnError = func(err error) { got = err }
+
+	items := []string{"a", "b"}
+	idx := signals.New(0)
+	item := signals.NewComputed(func() string { return items[idx.Get()] })
+	Render(s, func() Renderable { return Template("%v", item) })
+
+	// The computed panics while Set flushes it, not inside the view
+	idx.Set(5)
+	if got == nil || !strings.Contains(got.Error(), "index out of range") {
+		t.Fatalf("Expected OnError to receive the computed's panic, got %v", got)
+	}
+
+	idx.Set(1)
+	if got := s.Front.String(); got != "b\n" {
+		t.Errorf("Expected the view redrawn, got %q", got)
+	}
+}
+
+func TestScrollSignalRedraws(t *testing.T) {
+	s := newTestScreen(10, 1)
+	Render(s, func() Renderable {
+		return Template("first\nsecond")
+	})
+
+	s.Scroll(1)
+	if got := s.Front.String(); got != "second\n" {
+		t.Errorf("Expected scrolled view, got %q", got)
+	}
+
+	s.Scroll(-5)
+	if got := s.ScrollY.Get(); got != 0 {
+		t.Errorf("Expected scroll clamped at 0, got %d", got)
+	}
+	if got := s.Front.String(); got != "first\n" {
+		t.Errorf("Expected top of view, got %q", got)
+	}
+}
+
+func TestScrollClampsToContent(t *testing.T) {
+	s := newTestScreen(10, 3)
+	lines := signals.New("one\ntwo")
+	Render(s, func() Renderable { return Template(lines.Get()) })
+
+	// Shorter than the screen: nothing to scroll
+	s.Scroll(5)
+	if got := s.ScrollY.Get(); got != 0 {
+		t.Errorf("Expected a short document not to scroll, got offset %d", got)
+	}
+
+	lines.Set(strings.Repeat("x\n", 9) + "last")
+	if got := s.ContentHeight().Get(); got != 10 {
+		t.Errorf("Expected a content height of 10, got %d", got)
+	}
+	s.Scroll(100)
+	if got := s.ScrollY.Get(); got != 7 {
+		t.Errorf("Expected scrolling to stop at 10-3 = 7, got %d", got)
+	}
+	if got := s.Front.String(); got != "x\nx\nlast\n" {
+		t.Errorf("Expected the last screenful, got %q", got)
+	}
+}
+
+func TestScrollReclampsWhenContentShrinks(t *testing.T) {
+	s := newTestScreen(10, 3)
+	lines := signals.New(strings.Repeat("x\n", 9) + "last")
+	Render(s, func() Renderable { return Template(lines.Get()) })
+	s.Scroll(100)
+
+	lines.Set("one\ntwo")
+	if got := s.ScrollY.Get(); got != 0 {
+		t.Errorf("Expected the offset clamped back to 0, got %d", got)
+	}
+	if got := s.Front.String(); got != "one\ntwo\n\n" {
+		t.Errorf("Expected the short document from the top, got %q", got)
+	}
+}
+
+// countingGetter counts how often its value is read
+type countingGetter struct{ reads int }
+
+func (g *countingGetter) GetValue() interface{} {
+	g.reads++
+	return "below"
+}
+
+func TestRenderSkipsContentBelowScreen(t *testing.T) {
+	g := &countingGetter{}
+	r := Template("a\nb\nc\n%v", g)
+
+	// Drawing a screen doesn't walk what's below it...
+	renderNode(newTestScreen(10, 2), r.Root, r.Args, 0, 0)
+	if g.reads != 0 {
+		t.Errorf("Expected the hole below the screen skipped, got %d reads", g.reads)
+	}
+
+	// ...but the content height still counts it
+	if got := ContentHeight(r, 10); got != 4 {
+		t.Errorf("Expected a content height of 4, got %d", got)
+	}
+	s := newTestScreen(10, 2)
+	Render(s, func() Renderable { return r })
+	if got := s.ContentHeight().Get(); got != 4 {
+		t.Errorf("Expected the screen's content height of 4, got %d", got)
+	}
+}
+
+func TestScrollSkipsHeightPassWithLayouts(t *testing.T) {
+	s := newTestScreen(10, 2)
+	g := &countingGetter{}
+	rows := signals.New("one")
+	view := Col(Text("top"), rows)
+	Render(s, func() Renderable { return Template("%v\na\nb\nc\n%v", view, g) })
+
+	// Only resolving the arguments reads the hole below the screen: the
+	// unchanged layout doesn't make the height pass run again
+	g.reads = 0
+	s.Scroll(1)
+	if g.reads != 1 {
+		t.Errorf("Expected the hole read once when scrolling, got %d reads", g.reads)
+	}
+
+	// A layout that grows still updates the height
+	rows.Set("one\ntwo")
+	if got := s.ContentHeight().Get(); got != 7 {
+		t.Errorf("Expected a content height of 7, got %d", got)
+	}
+}
+
+func TestNestedStylesInherit(t *testing.T) {
+	s := newTestScreen(20, 1)
+	r := Template("**a __b #red(c)__**")
+	renderNode(s, r.Root, r.Args, 0, 0)
+
+	want := []basement.Style{
+		{Bold: true},
+		{Bold: true},
+		{Bold: true, Underline: true},
+		{Bold: true, Underline: true},
+		{Bold: true, Underline: true, Color: basement.GetColorCode("red")},
+	}
+	for x, w := range want {
+		if got := s.Back.Get(x, 0).Style; got != w {
+			t.Errorf("Cell %d: expected %+v, got %+v", x, w, got)
+		}
+	}
+}
+
+func BenchmarkRenderNestedStyles(b *testing.B) {
+	s := newTestScreen(80, 4)
+	r := Template("# Title with **bold __under ~~strike #red(red **deep**)~~__** text**\n\nA **paragraph** with #green(__nested #blue(**styles**)__) and %v", "#cyan(**hole**)")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		renderNode(s, r.Root, r.Args, 0, 0)
+	}
+}
+
+func TestSizeSignalsDriveComputeds(t *testing.T) {
+	s := newTestScreen(10, 2)
+	width, height := s.Size()
+	if width.Peek() != 10 || height.Peek() != 2 {
+		t.Fatalf("Expected the creation size, got %d x %d", width.Peek(), height.Peek())
+	}
+
+	area := signals.Combine2(width, height, func(w, h int) int { return w * h })
+	var seen []int
+	signals.CreateEffect(func() { seen = append(seen, area.Get()) })
+
+	s.resize(12, 3)
+	if last := seen[len(seen)-1]; last != 36 {
+		t.Errorf("Expected the dependent effect to see the new area 36, got %v", seen)
+	}
+}
+
+func TestResizeUpdatesSizeSignals(t *testing.T) {
+	s := newTestScreen(10, 2)
+	width, height := s.Size()
+
+	renders := 0
+	Render(s, func() Renderable {
+		renders++
+		return Template("%v x %v", width.Get(), height.Get())
+	})
+
+	resized := false
+	s.OnResize = func(w, h int) { resized = true }
+	s.resize(12, 3)
+
+	if got := s.Front.String(); got != "12 x 3\n\n\n" {
+		t.Errorf("Expected the view to re-render with the new size, got %q", got)
+	}
+	if renders != 2 {
+		t.Errorf("Expected the render effect to re-run once, got %d renders", renders)
+	}
+	if !resized {
+		t.Errorf("Expected OnResize to still be called")
+	}
+}
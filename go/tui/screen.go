@@ -3,13 +3,16 @@ package tui
 import (
 	"bufio"
 	"basement/basement"
+	"basement/signals"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"strconv"
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"golang.org/x/term"
 )
@@ -20,20 +23,109 @@ type Cell struct {
 	Style basement.Style
 }
 
+// Rect is a rectangular region of cells
+type Rect struct {
+	X, Y int
+	W, H int
+}
+
+// Contains reports whether the cell at x, y lies inside the rect
+func (r Rect) Contains(x, y int) bool {
+	return x >= r.X && x < r.X+r.W && y >= r.Y && y < r.Y+r.H
+}
+
+// Intersect returns the overlap of two rects (zero-sized if they are disjoint)
+func (r Rect) Intersect(o Rect) Rect {
+	x0, y0 := r.X, r.Y
+	if o.X > x0 {
+		x0 = o.X
+	}
+	if o.Y > y0 {
+		y0 = o.Y
+	}
+	x1, y1 := r.X+r.W, r.Y+r.H
+	if o.X+o.W < x1 {
+		x1 = o.X + o.W
+	}
+	if o.Y+o.H < y1 {
+		y1 = o.Y + o.H
+	}
+	if x1 < x0 {
+		x1 = x0
+	}
+	if y1 < y0 {
+		y1 = y0
+	}
+	return Rect{X: x0, Y: y0, W: x1 - x0, H: y1 - y0}
+}
+
 // Buffer represents a 2D grid of cells
 type Buffer struct {
 	Width  int
 	Height int
 	Cells  []Cell
+
+	// Clip stack: writes outside the top rect are dropped
+	clips []Rect
+
+	// Dirty tracking, per row, so a flush only diffs what may have changed.
+	// dirty: columns written or cleared since the last flush.
+	// drawn: columns written since the last flush; prevDrawn: in the flush before,
+	// which is what the next clear has to blank.
+	dirty     []span
+	drawn     []span
+	prevDrawn []span
+	full      bool // Clear and diff everything (new or resized buffer)
+}
+
+// span is an inclusive range of columns; lo > hi means empty
+type span struct {
+	lo, hi int
+}
+
+var emptySpan = span{lo: 1, hi: 0}
+
+func (sp *span) add(lo, hi int) {
+	if lo > hi {
+		return
+	}
+	if sp.lo > sp.hi {
+		sp.lo, sp.hi = lo, hi
+		return
+	}
+	if lo < sp.lo {
+		sp.lo = lo
+	}
+	if hi > sp.hi {
+		sp.hi = hi
+	}
 }
 
 // NewBuffer creates a new buffer of the given size
 func NewBuffer(width, height int) *Buffer {
-	return &Buffer{
+	b := &Buffer{
 		Width:  width,
 		Height: height,
 		Cells:  make([]Cell, width*height),
 	}
+	b.resetDirty()
+	return b
+}
+
+// resetDirty allocates empty dirty spans and requests a full clear and diff
+func (b *Buffer) resetDirty() {
+	b.dirty = make([]span, b.Height)
+	b.drawn = make([]span, b.Height)
+	b.prevDrawn = make([]span, b.Height)
+	for y := 0; y < b.Height; y++ {
+		b.dirty[y], b.drawn[y], b.prevDrawn[y] = emptySpan, emptySpan, emptySpan
+	}
+	b.full = true
+}
+
+// Invalidate marks the whole buffer dirty. Call it after writing to Cells directly.
+func (b *Buffer) Invalidate() {
+	b.full = true
 }
 
 // Set writes a rune and style to a specific coordinate
@@ -41,7 +133,211 @@ func (b *Buffer) Set(x, y int, ch rune, style basement.Style) {
 	if x < 0 || x >= b.Width || y < 0 || y >= b.Height {
 		return
 	}
+	if n := len(b.clips); n > 0 && !b.clips[n-1].Contains(x, y) {
+		return
+	}
 	b.Cells[y*b.Width+x] = Cell{Char: ch, Style: style}
+	b.dirty[y].add(x, x)
+	b.drawn[y].add(x, x)
+}
+
+// Blit copies src onto b with its top-left corner at (x, y), blank cells
+// included, clipped to b and its clip rect. A widget can draw into its own
+// Buffer once and be placed with Blit on every frame after that.
+func (b *Buffer) Blit(src *Buffer, x, y int) {
+	b.blit(src, x, y, false)
+}
+
+// BlitOver is Blit with transparency: cells of src that were never written
+// (Char 0, as NewBuffer leaves them) keep what b already shows. Use it to lay
+// an overlay or modal over a frame.
+func (b *Buffer) BlitOver(src *Buffer, x, y int) {
+	b.blit(src, x, y, true)
+}
+
+func (b *Buffer) blit(src *Buffer, x, y int, transparent bool) {
+	r := Rect{X: 0, Y: 0, W: b.Width, H: b.Height}
+	if n := len(b.clips); n > 0 {
+		r = r.Intersect(b.clips[n-1])
+	}
+	// Destination columns; src column = destination column - x
+	from, to := x, x+src.Width
+	if from < r.X {
+		from = r.X
+	}
+	if to > r.X+r.W {
+		to = r.X + r.W
+	}
+	if from >= to {
+		return
+	}
+
+	for sy := 0; sy < src.Height; sy++ {
+		dy := y + sy
+		if dy < r.Y || dy >= r.Y+r.H {
+			continue
+		}
+		row := b.Cells[dy*b.Width : (dy+1)*b.Width]
+		srcRow := src.Cells[sy*src.Width+from-x : sy*src.Width+to-x]
+		if transparent {
+			for i, c := range srcRow {
+				if c.Char != 0 {
+					row[from+i] = c
+				}
+			}
+		} else {
+			copy(row[from:to], srcRow)
+		}
+
+		// Don't leave half of a copied wide rune at either edge
+		if row[from].Char == wideTail && srcRow[0].Char != 0 {
+			row[from] = Cell{Char: ' ', Style: row[from].Style}
+		}
+		if last := row[to-1]; runeWidth(last.Char) == 2 && srcRow[len(srcRow)-1].Char != 0 {
+			row[to-1] = Cell{Char: ' ', Style: last.Style}
+		}
+		b.dirty[dy].add(from, to-1)
+		b.drawn[dy].add(from, to-1)
+	}
+}
+
+// FillRect sets every cell of the w x h rect at (x, y) to ch in style,
+// clipped to b and its clip rect. ch should be a single-column rune; use ' '
+// to clear a panel.
+func (b *Buffer) FillRect(x, y, w, h int, ch rune, style basement.Style) {
+	r := Rect{X: x, Y: y, W: w, H: h}.Intersect(Rect{X: 0, Y: 0, W: b.Width, H: b.Height})
+	if n := len(b.clips); n > 0 {
+		r = r.Intersect(b.clips[n-1])
+	}
+	if r.W == 0 {
+		return
+	}
+	cell := Cell{Char: ch, Style: style}
+	for dy := r.Y; dy < r.Y+r.H; dy++ {
+		row := b.Cells[dy*b.Width+r.X : dy*b.Width+r.X+r.W]
+		for i := range row {
+			row[i] = cell
+		}
+		b.dirty[dy].add(r.X, r.X+r.W-1)
+		b.drawn[dy].add(r.X, r.X+r.W-1)
+	}
+}
+
+// ScrollRegion shifts rows top through bottom (inclusive) up by lines, or down
+// if lines is negative, blanking the rows scrolled in. Rows outside the region
+// are left alone and whole rows move, ignoring the clip rect. Appending to a
+// log panel is one ScrollRegion plus one line of drawing.
+func (b *Buffer) ScrollRegion(top, bottom, lines int) {
+	if top < 0 {
+		top = 0
+	}
+	if bottom >= b.Height {
+		bottom = b.Height - 1
+	}
+	if top > bottom || lines == 0 {
+		return
+	}
+	w := b.Width
+	if n := bottom - top + 1; lines >= n || -lines >= n {
+		// Everything scrolls out
+		lines = n
+	} else if lines > 0 {
+		copy(b.Cells[top*w:(bottom+1-lines)*w], b.Cells[(top+lines)*w:(bottom+1)*w])
+	} else {
+		copy(b.Cells[(top-lines)*w:(bottom+1)*w], b.Cells[top*w:(bottom+1+lines)*w])
+	}
+
+	// Blank the rows that scrolled in
+	blankFrom, blankTo := bottom+1-lines, bottom
+	if lines < 0 {
+		blankFrom, blankTo = top, top-lines-1
+	}
+	for y := blankFrom; y <= blankTo; y++ {
+		row := b.Cells[y*w : (y+1)*w]
+		for i := range row {
+			row[i] = Cell{Char: ' '}
+		}
+	}
+	for y := top; y <= bottom; y++ {
+		b.dirty[y].add(0, w-1)
+		b.drawn[y].add(0, w-1)
+	}
+}
+
+// SetString writes s starting at (x, y) in one style and returns the column
+// after its last rune. The row and clip bounds are worked out once, so this is
+// much cheaper than calling Set per rune. s should not contain newlines.
+// Wide runes take two cells, the second holding wideTail; one cut in half by
+// the edge or clip is drawn as a blank.
+func (b *Buffer) SetString(x, y int, s string, style basement.Style) int {
+	lo, hi := 0, b.Width
+	if n := len(b.clips); n > 0 {
+		c := b.clips[n-1]
+		if y < c.Y || y >= c.Y+c.H {
+			return x + stringWidth(s)
+		}
+		if c.X > lo {
+			lo = c.X
+		}
+		if c.X+c.W < hi {
+			hi = c.X + c.W
+		}
+	}
+	if y < 0 || y >= b.Height {
+		return x + stringWidth(s)
+	}
+
+	row := b.Cells[y*b.Width : (y+1)*b.Width]
+	col := x
+	first, last := -1, -1
+	for i, r := range s {
+		if col >= hi {
+			// Past the right edge: count the rest without writing
+			col += stringWidth(s[i:])
+			break
+		}
+		w := runeWidth(r)
+		head, tail := r, wideTail
+		if w == 2 && (col < lo || col+1 >= hi) {
+			head, tail = ' ', ' '
+		}
+		for k := 0; k < w; k++ {
+			c := col + k
+			if c < lo || c >= hi {
+				continue
+			}
+			ch := head
+			if k == 1 {
+				ch = tail
+			}
+			row[c] = Cell{Char: ch, Style: style}
+			if first < 0 {
+				first = c
+			}
+			last = c
+		}
+		col += w
+	}
+	if first >= 0 {
+		b.dirty[y].add(first, last)
+		b.drawn[y].add(first, last)
+	}
+	return col
+}
+
+// PushClip restricts subsequent Set calls to r, intersected with the current clip
+func (b *Buffer) PushClip(r Rect) {
+	if n := len(b.clips); n > 0 {
+		r = b.clips[n-1].Intersect(r)
+	}
+	b.clips = append(b.clips, r)
+}
+
+// PopClip restores the clip region that was active before the last PushClip
+func (b *Buffer) PopClip() {
+	if n := len(b.clips); n > 0 {
+		b.clips = b.clips[:n-1]
+	}
 }
 
 // Get returns the cell at the given coordinate
@@ -72,6 +368,40 @@ func (b *Buffer) Resize(width, height int) {
 	b.Width = width
 	b.Height = height
 	b.Cells = newCells
+	b.resetDirty()
+}
+
+// String returns the buffer as plain text, one line per row with trailing spaces trimmed
+func (b *Buffer) String() string {
+	var sb strings.Builder
+	for y := 0; y < b.Height; y++ {
+		row := b.Cells[y*b.Width : (y+1)*b.Width]
+		for _, c := range row[:rowEnd(row)] {
+			if c.Char == wideTail {
+				continue
+			}
+			if c.Char == 0 {
+				sb.WriteRune(' ')
+			} else {
+				sb.WriteRune(c.Char)
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// rowEnd returns the length of row without its trailing unstyled blanks
+func rowEnd(row []Cell) int {
+	end := len(row)
+	for end > 0 {
+		c := row[end-1]
+		if (c.Char != ' ' && c.Char != 0) || c.Style != (basement.Style{}) {
+			break
+		}
+		end--
+	}
+	return end
 }
 
 // Screen manages the terminal display
@@ -80,14 +410,42 @@ type Screen struct {
 	Back  *Buffer // What we are drawing to
 	mu    sync.Mutex
 	out   *bufio.Writer
+	sink  *countingWriter // Under out, counts bytes sent to the terminal
+	stats Stats
 
 	// Input handling
 	inputChan <-chan KeyEvent
 	doneChan  chan struct{}
 	oldState  *State
+	restoreVT func() error // Undoes enableVirtualTerminal; nil if it had nothing to do
+	closeOnce sync.Once
+
+	// SIGINT/SIGTERM handling: restore the terminal, then re-raise
+	sigCh chan os.Signal
+
+	// Scrolling: Render reads these, so setting them redraws the screen
+	ScrollX *signals.Signal[int]
+	ScrollY *signals.Signal[int]
+
+	// Rows drawn by the last Render, see ContentHeight
+	contentHeight *signals.Signal[int]
+
+	// Walk nodes below the screen too, so renderNode returns the full height
+	// of the content; otherwise they are skipped
+	walkAll bool
 
-	// Scrolling
-	ScrollY int
+	// Document column at screen column 0 while Render draws, the horizontal
+	// scroll; tab stops are counted from the document's left edge
+	originX int
+
+	// Rule looks of the Render drawing, see RenderOptions.RuleLooks
+	ruleLooks map[basement.RuleStyle]RuleLook
+
+	// Toasts drawn over the view, see Toast
+	toasts *signals.Signal[[]*Toast]
+
+	// Cells highlighted when flushed, see SetSelection
+	selection *selection
 
 	// Capabilities
 	supportsItalic bool
@@ -97,6 +455,18 @@ type Screen struct {
 	resizeCh chan os.Signal
 	OnResize func(w, h int)
 
+	// Window focus handler, see OnFocus
+	focusMu sync.Mutex
+	onFocus func(FocusEvent)
+
+	// OnError, if set, receives panics from Render's view function (and the
+	// computeds it reads) as errors. The screen shows the error in place of
+	// the view and keeps running; the next update redraws the view. Without
+	// it a panic restores the terminal and crashes as usual.
+	OnError func(err error)
+	width    *signals.Signal[int]
+	height   *signals.Signal[int]
+
 	// Pre-allocated blank row for fast clear
 	blankRow []Cell
 
@@ -121,22 +491,28 @@ func NewScreen() *Screen {
 	s := &Screen{
 		Front:    NewBuffer(w, h),
 		Back:     NewBuffer(w, h),
-		out:      bufio.NewWriterSize(os.Stdout, 64*1024), // 64KB write buffer
+		sink:     &countingWriter{w: os.Stdout},
 		doneChan: make(chan struct{}),
 		blankRow: blankRow,
 		posBuf:   make([]byte, 0, 32),
+		ScrollX:  signals.New(0),
+		ScrollY:  signals.New(0),
+		width:    signals.New(w),
+		height:   signals.New(h),
+
+		contentHeight: signals.New(0),
+		toasts:        signals.New([]*Toast(nil)),
 	}
+	s.out = bufio.NewWriterSize(s.sink, 64*1024) // 64KB write buffer
 
 	// Check for capabilities
-	termEnv := os.Getenv("TERM")
-	if strings.Contains(termEnv, "xterm") ||
-	   strings.Contains(termEnv, "truecolor") ||
-	   strings.Contains(termEnv, "alacritty") ||
-	   strings.Contains(termEnv, "kitty") ||
-	   strings.Contains(termEnv, "screen") ||
-	   strings.Contains(termEnv, "tmux") {
-		s.supportsItalic = true
-		s.supportsStrike = true // Most modern terms support both
+	s.supportsItalic, s.supportsStrike = detectCaps(os.Getenv)
+
+	// Windows consoles print escape sequences literally until told otherwise
+	if restore, err := enableVirtualTerminal(); err == nil {
+		s.restoreVT = restore
+	} else {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to enable virtual terminal mode: %v\n", err)
 	}
 
 	// Enable raw mode
@@ -148,44 +524,157 @@ func NewScreen() *Screen {
 	}
 
 	// Start input loop
-	s.inputChan = StartInput(s.doneChan)
+	s.inputChan = s.splitFocus(StartInput(s.doneChan))
 
-	// Start SIGWINCH listener for terminal resize
+	// Start SIGWINCH listener for terminal resize (a size poller on Windows)
 	s.resizeCh = make(chan os.Signal, 1)
-	signal.Notify(s.resizeCh, syscall.SIGWINCH)
+	notifyResize(s.resizeCh, s.doneChan)
 	go s.handleResize()
 
-	// Hide cursor initially
-	s.out.WriteString("\x1b[?25l")
+	// Restore the terminal if the process is interrupted or terminated
+	s.sigCh = make(chan os.Signal, 1)
+	signal.Notify(s.sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go s.handleSignals()
+
+	// Hide cursor initially, and ask for focus in/out reports
+	s.out.WriteString("\x1b[?25l\x1b[?1004h")
 	s.out.Flush()
 
 	return s
 }
 
-// Close restores the terminal state
+// newHeadlessScreen creates a Screen backed only by its buffers. Output from
+// Render goes to out; the terminal is never touched.
+func newHeadlessScreen(w, h int, out io.Writer) *Screen {
+	s := &Screen{
+		Front:          NewBuffer(w, h),
+		Back:           NewBuffer(w, h),
+		sink:           &countingWriter{w: out},
+		posBuf:         make([]byte, 0, 32),
+		ScrollX:        signals.New(0),
+		ScrollY:        signals.New(0),
+		width:          signals.New(w),
+		height:         signals.New(h),
+		contentHeight:  signals.New(0),
+		toasts:         signals.New([]*Toast(nil)),
+		supportsItalic: true,
+		supportsStrike: true,
+	}
+	s.out = bufio.NewWriter(s.sink)
+	return s
+}
+
+// Stats counts the screen's rendering work since it was created
+type Stats struct {
+	Frames       int           // Frames flushed to the terminal
+	CellsDiffed  int           // Cells compared against the previous frame
+	CellsChanged int           // Cells that differed and were redrawn
+	BytesWritten int64         // Bytes of output, escapes included
+	FrameTime    time.Duration // Total time spent drawing and flushing frames
+}
+
+// AvgFrameTime returns the mean time per frame
+func (st Stats) AvgFrameTime() time.Duration {
+	if st.Frames == 0 {
+		return 0
+	}
+	return st.FrameTime / time.Duration(st.Frames)
+}
+
+// Stats returns a snapshot of the rendering counters. CellsChanged close to
+// CellsDiffed on every frame means the app is doing full redraws.
+func (s *Screen) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// countingWriter passes writes through to w, counting the bytes
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Scroll moves the view down by dy rows (up if negative), stopping at the
+// top and where the last row of content reaches the bottom of the screen
+func (s *Screen) Scroll(dy int) {
+	y := s.ScrollY.Peek() + dy
+	s.ScrollY.Set(clampScroll(y, s.height.Peek(), s.contentHeight.Peek()))
+}
+
+// ContentHeight returns a signal holding how many rows the view mounted with
+// Render took up in its last frame, visible or not
+func (s *Screen) ContentHeight() *signals.Signal[int] {
+	return s.contentHeight
+}
+
+// Close restores the terminal state. It is safe to call more than once.
 func (s *Screen) Close() {
-	// Stop resize signal before acquiring lock
-	signal.Stop(s.resizeCh)
+	s.closeOnce.Do(s.restore)
+}
+
+// Recover restores the terminal if the calling goroutine is panicking, then
+// re-panics with the same value. Use it as `defer screen.Recover()` right
+// after creating the screen so a crash doesn't leave the terminal in raw mode.
+func (s *Screen) Recover() {
+	if r := recover(); r != nil {
+		s.Close()
+		panic(r)
+	}
+}
+
+// restore does the work of Close
+func (s *Screen) restore() {
+	// Stop signals before acquiring lock
+	if s.resizeCh != nil {
+		stopResize(s.resizeCh)
+	}
+	if s.sigCh != nil {
+		signal.Stop(s.sigCh)
+	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Signal input loop and resize handler to stop
-	close(s.doneChan)
+	// Signal input loop, resize and signal handlers to stop
+	if s.doneChan != nil {
+		close(s.doneChan)
+	}
 
-	// Show cursor
-	s.out.WriteString("\x1b[?25h")
+	// Show cursor, stop focus reports
+	s.out.WriteString("\x1b[?25h\x1b[?1004l")
 
 	// Move cursor to bottom (simple heuristic)
 	fmt.Fprintf(s.out, "\x1b[%dH", s.Back.Height+1)
 	s.out.Flush()
 
-	// Restore terminal mode
+	// Restore terminal mode, then the console modes from before raw mode
 	if s.oldState != nil {
-		disableRawMode(os.Stdin, s.oldState)
+		restoreMode(s.oldState)
+	}
+	if s.restoreVT != nil {
+		s.restoreVT()
 	}
 }
 
+// Terminal and process hooks, swapped out by tests
+var (
+	restoreMode = func(st *State) error { return disableRawMode(os.Stdin, st) }
+	raise       = raiseSignal
+
+	terminalSize = func() (int, int, error) { return term.GetSize(int(os.Stdout.Fd())) }
+
+	// Timers
+	after     = time.After
+	afterFunc = time.AfterFunc
+)
+
 // OnKey registers a callback for key events
 func (s *Screen) OnKey(fn func(KeyEvent)) {
 	go func() {
@@ -195,37 +684,148 @@ func (s *Screen) OnKey(fn func(KeyEvent)) {
 	}()
 }
 
+// OnKeyBatched registers a callback for key events delivered in batches:
+// everything typed within about a frame of the first event arrives as one
+// slice. Under fast key repeat, a handler can then act once per batch (see
+// CoalesceKeys) instead of once per event. Use either OnKey or OnKeyBatched.
+func (s *Screen) OnKeyBatched(fn func([]KeyEvent)) {
+	go batchKeys(s.inputChan, keyBatchWindow, fn)
+}
+
+// OnFocus registers a callback for the terminal window gaining or losing
+// focus, e.g. to pause animations while the window is in the background.
+// Terminals without focus reporting never call it.
+func (s *Screen) OnFocus(fn func(FocusEvent)) {
+	s.focusMu.Lock()
+	defer s.focusMu.Unlock()
+	s.onFocus = fn
+}
+
+// splitFocus passes focus reports from in to the OnFocus callback and every
+// other event on to the returned channel. Keys nobody has read yet are queued,
+// so they never hold up a focus report, and the screen closing drops them.
+func (s *Screen) splitFocus(in <-chan KeyEvent) <-chan KeyEvent {
+	out := make(chan KeyEvent)
+	go func() {
+		defer close(out)
+		var pending []KeyEvent
+		for in != nil || len(pending) > 0 {
+			// Only offer a key while one is waiting
+			var send chan<- KeyEvent
+			var next KeyEvent
+			if len(pending) > 0 {
+				send, next = out, pending[0]
+			}
+
+			select {
+			case <-s.doneChan:
+				return
+			case send <- next:
+				pending = pending[1:]
+			case ev, ok := <-in:
+				if !ok {
+					in = nil
+					continue
+				}
+				if ev.Key != keyFocusIn && ev.Key != keyFocusOut {
+					pending = append(pending, ev)
+					continue
+				}
+				s.focusMu.Lock()
+				fn := s.onFocus
+				s.focusMu.Unlock()
+				if fn != nil {
+					fn(FocusEvent{Focused: ev.Key == keyFocusIn})
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// Run passes key events to onKey on the calling goroutine until it returns
+// true or input ends, then closes the screen. It replaces OnKey plus a quit
+// channel at the end of main; don't combine the two, as both read the same
+// events. Renders and background updates carry on while Run blocks.
+func (s *Screen) Run(onKey func(KeyEvent) (done bool)) {
+	defer s.Close()
+	for ev := range s.inputChan {
+		if onKey(ev) {
+			return
+		}
+	}
+}
+
+// handleSignals restores the terminal on SIGINT/SIGTERM and then re-raises the
+// signal, so the process still exits the way the sender expects
+func (s *Screen) handleSignals() {
+	select {
+	case <-s.doneChan:
+	case sig := <-s.sigCh:
+		s.Close()
+		// Close stopped our handler, so this gets the default action
+		raise(sig)
+	}
+}
+
 // handleResize listens for SIGWINCH and resizes buffers
+// A burst of SIGWINCH while the window is dragged is applied as one resize,
+// at the size read once the burst has been quiet for resizeDebounce.
 func (s *Screen) handleResize() {
+	var settled <-chan time.Time
 	for {
 		select {
 		case <-s.doneChan:
 			return
 		case <-s.resizeCh:
-			w, h, err := term.GetSize(int(os.Stdout.Fd()))
+			// Restart the quiet period; an earlier one that ends is ignored
+			settled = after(resizeDebounce)
+		case <-settled:
+			settled = nil
+			w, h, err := terminalSize()
 			if err != nil {
 				continue
 			}
-			s.mu.Lock()
-			s.Front.Resize(w, h)
-			s.Back.Resize(w, h)
-			// Update blank row for new width
-			s.blankRow = make([]Cell, w)
-			for i := range s.blankRow {
-				s.blankRow[i] = Cell{Char: ' '}
-			}
-			// Force full redraw by invalidating front buffer
-			for i := range s.Front.Cells {
-				s.Front.Cells[i] = Cell{}
-			}
-			s.mu.Unlock()
-			if s.OnResize != nil {
-				s.OnResize(w, h)
-			}
+			s.resize(w, h)
 		}
 	}
 }
 
+// resizeDebounce is how long SIGWINCH must stay quiet before resizing
+const resizeDebounce = 50 * time.Millisecond
+
+// resize resizes the buffers, then updates the size signals and calls OnResize
+// as one update, so a view reading both sizes re-renders once
+func (s *Screen) resize(w, h int) {
+	s.mu.Lock()
+	s.Front.Resize(w, h)
+	s.Back.Resize(w, h)
+	// Update blank row for new width
+	s.blankRow = make([]Cell, w)
+	for i := range s.blankRow {
+		s.blankRow[i] = Cell{Char: ' '}
+	}
+	// Force full redraw by invalidating front buffer
+	for i := range s.Front.Cells {
+		s.Front.Cells[i] = Cell{}
+	}
+	s.mu.Unlock()
+
+	signals.Batch(func() {
+		s.width.Set(w)
+		s.height.Set(h)
+		if s.OnResize != nil {
+			s.OnResize(w, h)
+		}
+	})
+}
+
+// Size returns signals holding the screen's width and height. A view that
+// reads them re-renders when the terminal is resized.
+func (s *Screen) Size() (*signals.Signal[int], *signals.Signal[int]) {
+	return s.width, s.height
+}
+
 // Clear clears the back buffer
 func (s *Screen) Clear() {
 	s.mu.Lock()
@@ -244,8 +844,19 @@ func (s *Screen) clearBackBuf() {
 			s.blankRow[i] = Cell{Char: ' '}
 		}
 	}
+	back := s.Back
 	for y := 0; y < h; y++ {
-		copy(cells[y*w:(y+1)*w], s.blankRow)
+		if back.full {
+			copy(cells[y*w:(y+1)*w], s.blankRow)
+			back.prevDrawn[y] = emptySpan
+			continue
+		}
+		// Only what was drawn last frame can be non-blank
+		if sp := back.prevDrawn[y]; sp.lo <= sp.hi {
+			copy(cells[y*w+sp.lo:y*w+sp.hi+1], s.blankRow[sp.lo:sp.hi+1])
+			back.dirty[y].add(sp.lo, sp.hi)
+		}
+		back.prevDrawn[y] = emptySpan
 	}
 }
 
@@ -253,27 +864,32 @@ func (s *Screen) clearBackBuf() {
 func (s *Screen) Render() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	start := time.Now()
 	s.renderUnlocked()
+	s.stats.FrameTime += time.Since(start)
 }
 
 // Frame executes draw under a single lock: clear, draw, diff+flush.
 // Use drawTextUnlocked inside the draw callback.
 func (s *Screen) Frame(draw func()) {
 	s.mu.Lock()
+	defer s.mu.Unlock() // Also on panic, so Close can still restore the terminal
+	start := time.Now()
 
-	// Clear
+	// Clear (and drop any clip left over from a panicking draw)
 	s.clearBackBuf()
+	s.Back.clips = s.Back.clips[:0]
 
 	// Draw to back buffer
 	draw()
 
 	// Diff and flush
 	s.renderUnlocked()
-
-	s.mu.Unlock()
+	s.stats.FrameTime += time.Since(start)
 }
 
 func (s *Screen) renderUnlocked() {
+	written := s.sink.n
 	w := s.Back.Width
 	h := s.Back.Height
 	backCells := s.Back.Cells
@@ -284,13 +900,42 @@ func (s *Screen) renderUnlocked() {
 	styleActive := false
 
 	for y := 0; y < h; y++ {
+		// Skip rows with nothing written or cleared since the last flush
+		lo, hi := 0, w-1
+		if !s.Back.full {
+			sp := s.Back.dirty[y]
+			if sp.lo > sp.hi {
+				continue
+			}
+			lo, hi = sp.lo, sp.hi
+		}
+
 		rowOff := y * w
-		for x := 0; x < w; x++ {
+		s.stats.CellsDiffed += hi - lo + 1
+		for x := lo; x <= hi; x++ {
 			idx := rowOff + x
-			backCell := backCells[idx]
+			backCell := s.selected(backCells[idx], x, y)
 
 			if backCell != frontCells[idx] {
-				// Move cursor if needed
+				s.stats.CellsChanged++
+
+				// The wide glyph to the left already covers this cell
+				if backCell.Char == wideTail {
+					frontCells[idx] = backCell
+					continue
+				}
+
+				// Move cursor if needed. A short gap of unchanged cells on
+				// this row is rewritten instead, costing fewer bytes. Being
+				// unchanged, they are the same in the front buffer, which
+				// also has the selection applied.
+				if curY == y && curX < x && x-curX <= bridgeLimit &&
+					styleActive && canBridge(frontCells[rowOff+curX:idx], lastStyle) {
+					for _, c := range frontCells[rowOff+curX : idx] {
+						s.out.WriteRune(c.Char)
+					}
+					curX = x
+				}
 				if curX != x || curY != y {
 					s.writeCursorPos(y+1, x+1)
 					curX, curY = x, y
@@ -311,7 +956,7 @@ func (s *Screen) renderUnlocked() {
 					ch = ' '
 				}
 				s.out.WriteRune(ch)
-				curX++
+				curX += runeWidth(ch)
 
 				frontCells[idx] = backCell
 			}
@@ -324,6 +969,68 @@ func (s *Screen) renderUnlocked() {
 	}
 
 	s.out.Flush()
+	s.Back.flushed()
+
+	s.stats.Frames++
+	s.stats.BytesWritten += s.sink.n - written
+}
+
+// bridgeLimit is the widest gap of unchanged cells rewritten rather than
+// skipped with a cursor move ("\x1b[r;cH" takes at least 6 bytes)
+const bridgeLimit = 6
+
+// canBridge reports whether cells can be rewritten as they are in style:
+// single-width, already drawn characters needing no style escape
+func canBridge(cells []Cell, style basement.Style) bool {
+	for _, c := range cells {
+		if c.Style != style || c.Char <= 0 || runeWidth(c.Char) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// flushed resets dirty tracking once the buffer has been diffed to the screen
+func (b *Buffer) flushed() {
+	for y := 0; y < b.Height; y++ {
+		b.prevDrawn[y].add(b.drawn[y].lo, b.drawn[y].hi)
+		b.drawn[y] = emptySpan
+		b.dirty[y] = emptySpan
+	}
+	b.full = false
+}
+
+// writeANSI writes b row by row as styled text, without cursor positioning
+func (s *Screen) writeANSI(b *Buffer) {
+	for y := 0; y < b.Height; y++ {
+		row := b.Cells[y*b.Width : (y+1)*b.Width]
+
+		var lastStyle basement.Style
+		styleActive := false
+		for _, c := range row[:rowEnd(row)] {
+			// The right half of a wide rune was written with its left half
+			if c.Char == wideTail {
+				continue
+			}
+			if c.Style != lastStyle {
+				if styleActive {
+					s.out.WriteString("\x1b[0m")
+				}
+				s.writeStyle(c.Style)
+				lastStyle = c.Style
+				styleActive = c.Style != (basement.Style{})
+			}
+			ch := c.Char
+			if ch == 0 {
+				ch = ' '
+			}
+			s.out.WriteRune(ch)
+		}
+		if styleActive {
+			s.out.WriteString("\x1b[0m")
+		}
+		s.out.WriteByte('\n')
+	}
 }
 
 // writeCursorPos writes ANSI cursor position without fmt.Fprintf overhead
@@ -344,14 +1051,12 @@ func (s *Screen) writeStyle(st basement.Style) {
 	if st.Dim {
 		s.out.WriteString("\x1b[2m")
 	}
-	if st.Italic {
-		if s.supportsItalic {
-			s.out.WriteString("\x1b[3m")
-		} else {
-			s.out.WriteString("\x1b[2m") // Fallback to Dim
-		}
+	if st.Italic && s.supportsItalic {
+		s.out.WriteString("\x1b[3m")
 	}
-	if st.Underline {
+	// Without italic, fall back to underline (as man pages do) rather than
+	// Dim, so dimmed and italic text stay distinguishable
+	if st.Underline || st.Italic && !s.supportsItalic {
 		s.out.WriteString("\x1b[4m")
 	}
 	if st.Strike {
@@ -366,6 +1071,9 @@ func (s *Screen) writeStyle(st basement.Style) {
 	if st.Blink {
 		s.out.WriteString("\x1b[5m")
 	}
+	if st.Hidden {
+		s.out.WriteString("\x1b[8m")
+	}
 	if st.Color != "" {
 		s.out.WriteString(st.Color)
 	}
@@ -374,6 +1082,14 @@ func (s *Screen) writeStyle(st basement.Style) {
 	}
 }
 
+// BlitBuffer copies src onto the back buffer at (destX, destY), see
+// Buffer.Blit. Inside Frame, call s.Back.Blit instead.
+func (s *Screen) BlitBuffer(src *Buffer, destX, destY int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Back.Blit(src, destX, destY)
+}
+
 // DrawText draws a string to the back buffer at x, y
 func (s *Screen) DrawText(x, y int, text string, style basement.Style) {
 	s.mu.Lock()
@@ -383,14 +1099,15 @@ func (s *Screen) DrawText(x, y int, text string, style basement.Style) {
 
 // drawTextUnlocked is the lock-free version for use within Frame()
 func (s *Screen) drawTextUnlocked(x, y int, text string, style basement.Style) {
-	col := x
-	for _, r := range text {
-		if r == '\n' {
-			y++
-			col = x
-			continue
+	text = expandTabs(text, x+s.originX)
+	for {
+		i := strings.IndexByte(text, '\n')
+		if i < 0 {
+			s.Back.SetString(x, y, text, style)
+			return
 		}
-		s.Back.Set(col, y, r, style)
-		col++
+		s.Back.SetString(x, y, text[:i], style)
+		text = text[i+1:]
+		y++
 	}
 }
@@ -2,7 +2,15 @@ package tui
 
 import (
 	"basement/basement"
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func TestBuffer(t *testing.T) {
@@ -41,3 +49,584 @@ func TestScreen(t *testing.T) {
 		t.Errorf("DrawText failed")
 	}
 }
+
+func TestBufferSetStringClips(t *testing.T) {
+	b := NewBuffer(5, 2)
+	if end := b.SetString(3, 0, "héllo", basement.Style{}); end != 8 {
+		t.Errorf("Expected the returned column to count every rune (8), got %d", end)
+	}
+	b.SetString(-2, 1, "abcd", basement.Style{})
+	if got := b.String(); got != "   hé\ncd\n" {
+		t.Errorf("Expected the string clipped to each row, got %q", got)
+	}
+	if b.dirty[0] != (span{3, 4}) || b.dirty[1] != (span{0, 1}) {
+		t.Errorf("Expected dirty spans only over written cells, got %v %v", b.dirty[0], b.dirty[1])
+	}
+
+	// The clip rect narrows the writable columns and rows
+	b = NewBuffer(10, 2)
+	b.PushClip(Rect{X: 2, Y: 0, W: 3, H: 1})
+	b.SetString(0, 0, "abcdefg", basement.Style{})
+	b.SetString(0, 1, "outside", basement.Style{})
+	if got := b.String(); got != "  cde\n\n" {
+		t.Errorf("Expected clipped write, got %q", got)
+	}
+}
+
+func TestWideRunesAdvanceTwoColumns(t *testing.T) {
+	var out strings.Builder
+	s := newHeadlessScreen(6, 1, &out)
+	s.Frame(func() { s.drawTextUnlocked(0, 0, "a中b", basement.Style{}) })
+
+	if got := s.Front.String(); got != "a中b\n" {
+		t.Errorf("Expected the wide rune to fill two cells, got %q", got)
+	}
+	// One cursor move for the whole row: after 中 the terminal is already at column 4
+	if n := strings.Count(out.String(), "H"); n != 1 {
+		t.Errorf("Expected a single cursor move, got %d in %q", n, out.String())
+	}
+
+	// A wide rune cut by the right edge leaves a blank, not half a glyph
+	b := NewBuffer(3, 1)
+	if end := b.SetString(0, 0, "ab中", basement.Style{}); end != 4 {
+		t.Errorf("Expected the returned column to count display width (4), got %d", end)
+	}
+	if c := b.Get(2, 0).Char; c != ' ' {
+		t.Errorf("Expected the clipped wide rune blanked, got %q", c)
+	}
+}
+
+func TestWriteStyleItalicFallback(t *testing.T) {
+	var out strings.Builder
+	s := newHeadlessScreen(1, 1, &out)
+	write := func(st basement.Style) string {
+		out.Reset()
+		s.writeStyle(st)
+		s.out.Flush()
+		return out.String()
+	}
+
+	if got := write(basement.Style{Italic: true, Dim: true}); got != "\x1b[2m\x1b[3m" {
+		t.Errorf("Supported: expected dim and italic, got %q", got)
+	}
+
+	s.supportsItalic = false
+	if got := write(basement.Style{Italic: true}); got != "\x1b[4m" {
+		t.Errorf("Unsupported: expected underline fallback, got %q", got)
+	}
+	if got := write(basement.Style{Italic: true, Dim: true, Underline: true}); got != "\x1b[2m\x1b[4m" {
+		t.Errorf("Unsupported: expected dim kept and underline once, got %q", got)
+	}
+}
+
+func TestDetectCaps(t *testing.T) {
+	tests := []struct {
+		env    map[string]string
+		italic bool
+	}{
+		{map[string]string{"TERM": "xterm-256color"}, true},
+		{map[string]string{"TERM": "xterm-kitty"}, true},
+		{map[string]string{"TERM": "linux", "COLORTERM": "truecolor"}, false},
+		{map[string]string{"TERM": "dumb"}, false},
+		{map[string]string{"TERM": "screen-256color"}, false},
+		{map[string]string{"TERM": "screen-256color", "TMUX": "/tmp/tmux-0/default,1,0"}, true},
+		{map[string]string{"TERM": "rxvt-unicode", "COLORTERM": "truecolor"}, true},
+		{map[string]string{"TERM": "vt100"}, false},
+		// Substring matches used to accept these
+		{map[string]string{"TERM": "notxterm"}, false},
+	}
+	for _, tt := range tests {
+		italic, strike := detectCaps(func(k string) string { return tt.env[k] })
+		if italic != tt.italic || strike != tt.italic {
+			t.Errorf("%v: expected italic and strike %v, got %v and %v", tt.env, tt.italic, italic, strike)
+		}
+	}
+}
+
+func TestTabsExpandToTabStops(t *testing.T) {
+	s := newHeadlessScreen(20, 3, io.Discard)
+	s.drawTextUnlocked(0, 0, "\tx", basement.Style{})
+	s.drawTextUnlocked(0, 1, "abcde\tx", basement.Style{})
+	if s.Back.Get(4, 0).Char != 'x' || s.Back.Get(8, 1).Char != 'x' {
+		t.Errorf("Expected x at the next multiple of 4, got %q", s.Back.String())
+	}
+
+	SetTabWidth(2)
+	defer SetTabWidth(4)
+	s.drawTextUnlocked(0, 2, "\tx", basement.Style{})
+	if s.Back.Get(2, 2).Char != 'x' {
+		t.Errorf("Expected x at column 2 with a tab width of 2, got %q", s.Back.String())
+	}
+}
+
+func TestTabsExpandFromStartColumn(t *testing.T) {
+	// Text drawn at column 3 reaches the stop at 4, on every line
+	s := newHeadlessScreen(20, 2, io.Discard)
+	s.drawTextUnlocked(3, 0, "\tx\n\ty", basement.Style{})
+	if s.Back.Get(4, 0).Char != 'x' || s.Back.Get(4, 1).Char != 'y' {
+		t.Errorf("Expected x and y at column 4, got %q", s.Back.String())
+	}
+
+	// Inline text after other nodes, and a hole, continue from where they start
+	got := RenderToString(Template("ab **c**\tx %v", "d\te"), 20, 1)
+	if want := "ab c    x d e\n"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestTabsKeepTheirStopsWhenScrolled(t *testing.T) {
+	s := newTestScreen(20, 1)
+	s.ScrollX.Set(2)
+	Render(s, func() Renderable { return Template("ab\tcd\te") })
+	// The stops stay at document columns 4 and 8, now screen columns 2 and 6
+	if got := s.Back.String(); got != "  cd  e\n" {
+		t.Errorf("Expected the tabs to end at the document's stops, got %q", got)
+	}
+
+	// Drawn left of the screen, a tab still advances to the next stop only
+	s = newTestScreen(20, 1)
+	s.drawTextUnlocked(-3, 0, "\tx", basement.Style{})
+	if got := s.Back.String(); got != "x\n" {
+		t.Errorf("Expected the tab from column -3 to stop at 0, got %q", got)
+	}
+}
+
+func TestTabsInLayoutContent(t *testing.T) {
+	// A plain string in a box is measured and clipped with its tabs expanded
+	s := renderHeadless(Template("%v", Box("\tx", true, 0)), 10, 3)
+	if got := s.Back.String(); got != "┌─────┐\n│    x│\n└─────┘\n" {
+		t.Errorf("Expected the tab to advance to column 4 inside the box, got %q", got)
+	}
+}
+
+func TestBufferBlit(t *testing.T) {
+	src := NewBuffer(3, 2)
+	src.SetString(0, 0, "abc", basement.Style{Bold: true})
+	src.SetString(0, 1, "def", basement.Style{})
+
+	dst := NewBuffer(6, 3)
+	dst.Blit(src, 2, 1)
+	if got := dst.String(); got != "\n  abc\n  def\n" {
+		t.Errorf("Expected src placed at (2, 1), got %q", got)
+	}
+	if !dst.Get(2, 1).Style.Bold || dst.dirty[1] != (span{2, 4}) {
+		t.Errorf("Expected styles copied and the row marked dirty, got %+v %v", dst.Get(2, 1), dst.dirty[1])
+	}
+
+	// Clipped by the buffer edges on every side
+	dst = NewBuffer(4, 2)
+	dst.Blit(src, -1, 1)
+	dst.Blit(src, 3, -1)
+	if got := dst.String(); got != "   d\nbc\n" {
+		t.Errorf("Expected clipped copies, got %q", got)
+	}
+
+	// And by the clip rect
+	dst = NewBuffer(6, 2)
+	dst.PushClip(Rect{X: 0, Y: 0, W: 2, H: 1})
+	dst.Blit(src, 0, 0)
+	if got := dst.String(); got != "ab\n\n" {
+		t.Errorf("Expected the copy limited to the clip rect, got %q", got)
+	}
+}
+
+func TestBufferBlitOver(t *testing.T) {
+	// An overlay with a hole: the middle cell is never written
+	src := NewBuffer(3, 2)
+	src.SetString(0, 0, "[", basement.Style{})
+	src.SetString(2, 0, "]", basement.Style{})
+	src.SetString(0, 1, "---", basement.Style{})
+
+	dst := NewBuffer(5, 3)
+	for y := 0; y < 3; y++ {
+		dst.SetString(0, y, "xxxxx", basement.Style{})
+	}
+	dst.BlitOver(src, 0, 0) // Top-left corner
+	dst.BlitOver(src, 3, 2) // Bottom-right, clipped off both edges
+	if got := dst.String(); got != "[x]xx\n---xx\nxxx[x\n" {
+		t.Errorf("Expected unwritten cells to stay transparent, got %q", got)
+	}
+}
+
+func TestBufferScrollRegion(t *testing.T) {
+	fill := func() *Buffer {
+		b := NewBuffer(3, 5)
+		for y, s := range []string{"aaa", "bbb", "ccc", "ddd", "eee"} {
+			b.SetString(0, y, s, basement.Style{})
+		}
+		return b
+	}
+
+	b := fill()
+	b.ScrollRegion(1, 3, 1)
+	if got := b.String(); got != "aaa\nccc\nddd\n\neee\n" {
+		t.Errorf("Expected rows 1-3 scrolled up, got %q", got)
+	}
+	if b.Get(0, 3).Char != ' ' || b.dirty[3] != (span{0, 2}) {
+		t.Errorf("Expected a blank, dirty row scrolled in, got %+v %v", b.Get(0, 3), b.dirty[3])
+	}
+
+	b = fill()
+	b.ScrollRegion(1, 3, -2)
+	if got := b.String(); got != "aaa\n\n\nbbb\neee\n" {
+		t.Errorf("Expected rows 1-3 scrolled down by 2, got %q", got)
+	}
+
+	b = fill()
+	b.ScrollRegion(1, 3, 5)
+	if got := b.String(); got != "aaa\n\n\n\neee\n" {
+		t.Errorf("Expected the region blanked, got %q", got)
+	}
+}
+
+func TestBufferFillRect(t *testing.T) {
+	b := NewBuffer(5, 3)
+	b.FillRect(1, 1, 3, 5, '#', basement.Style{Bold: true})
+	if got := b.String(); got != "\n ###\n ###\n" {
+		t.Errorf("Expected a filled rect clipped at the bottom, got %q", got)
+	}
+	if !b.Get(2, 2).Style.Bold || b.dirty[1] != (span{1, 3}) {
+		t.Errorf("Expected the style applied and the row marked dirty, got %+v %v", b.Get(2, 2), b.dirty[1])
+	}
+
+	b.PushClip(Rect{X: 0, Y: 0, W: 2, H: 3})
+	b.FillRect(0, 0, 5, 3, ' ', basement.Style{})
+	if got := b.String(); got != "\n  ##\n  ##\n" {
+		t.Errorf("Expected the clear limited to the clip rect, got %q", got)
+	}
+}
+
+func TestStatsCountFrames(t *testing.T) {
+	var out bytes.Buffer
+	s := newHeadlessScreen(10, 2, &out)
+
+	s.Frame(func() { s.drawTextUnlocked(0, 0, "hello", basement.Style{}) })
+	st := s.Stats()
+	// The first frame paints every cell, blanks included
+	if st.Frames != 1 || st.CellsDiffed != 20 || st.CellsChanged != 20 {
+		t.Errorf("First frame: expected 1 frame, 20 cells diffed and changed, got %+v", st)
+	}
+
+	// A one-letter change only diffs the dirty row span and redraws one cell
+	s.Frame(func() { s.drawTextUnlocked(0, 0, "hallo", basement.Style{}) })
+	st = s.Stats()
+	if st.Frames != 2 || st.CellsDiffed != 25 || st.CellsChanged != 21 {
+		t.Errorf("Second frame: expected 2 frames, 25 cells diffed, 21 changed, got %+v", st)
+	}
+	if st.BytesWritten != int64(out.Len()) {
+		t.Errorf("Expected %d bytes written, got %d", out.Len(), st.BytesWritten)
+	}
+	if st.FrameTime <= 0 || st.AvgFrameTime() != st.FrameTime/2 {
+		t.Errorf("Expected frame times to be recorded, got %v (avg %v)", st.FrameTime, st.AvgFrameTime())
+	}
+}
+
+func TestFrameDiffsOnlyDirtyRegion(t *testing.T) {
+	var out strings.Builder
+	s := newHeadlessScreen(20, 6, &out)
+
+	draw := func(extra bool) func() {
+		return func() {
+			s.drawTextUnlocked(0, 0, "hello", basement.Style{})
+			if extra {
+				s.Back.Set(10, 3, 'x', basement.Style{})
+			}
+		}
+	}
+
+	s.Frame(draw(false))
+
+	// Corrupt a row nothing draws on: a limited diff never looks at it
+	s.Front.Cells[5*20+7] = Cell{Char: '?'}
+
+	out.Reset()
+	s.Frame(draw(true))
+
+	if got := out.String(); got != "\x1b[4;11Hx\x1b[0m" {
+		t.Errorf("Expected only the changed cell to be written, got %q", got)
+	}
+	if s.Front.Get(7, 5).Char != '?' {
+		t.Errorf("Expected the untouched row to be skipped by the diff")
+	}
+
+	// Removing the cell again clears just that cell
+	out.Reset()
+	s.Frame(draw(false))
+	if got := out.String(); got != "\x1b[4;11H \x1b[0m" {
+		t.Errorf("Expected the removed cell to be blanked, got %q", got)
+	}
+}
+
+func TestRecoverRestoresTerminal(t *testing.T) {
+	var out bytes.Buffer
+	s := newHeadlessScreen(10, 2, &out)
+
+	var repanicked interface{}
+	func() {
+		defer func() { repanicked = recover() }()
+		defer s.Recover()
+		panic("boom")
+	}()
+
+	if repanicked != "boom" {
+		t.Errorf("Expected the original panic to be re-raised, got %v", repanicked)
+	}
+	if !strings.Contains(out.String(), "\x1b[?25h") {
+		t.Errorf("Expected the cursor to be shown on panic, got %q", out.String())
+	}
+
+	// A later deferred Close must not restore twice
+	n := out.Len()
+	s.Close()
+	if out.Len() != n {
+		t.Errorf("Expected Close after Recover to be a no-op")
+	}
+}
+
+func TestSignalRestoresTerminal(t *testing.T) {
+	var out bytes.Buffer
+	s := newHeadlessScreen(10, 2, &out)
+	s.oldState = &State{}
+	s.doneChan = make(chan struct{})
+	s.sigCh = make(chan os.Signal, 1)
+
+	var restored *State
+	raised := make(chan os.Signal, 1)
+	defer func(m func(*State) error, r func(os.Signal)) { restoreMode, raise = m, r }(restoreMode, raise)
+	restoreMode = func(st *State) error { restored = st; return nil }
+	raise = func(sig os.Signal) { raised <- sig }
+
+	go s.handleSignals()
+	s.sigCh <- syscall.SIGTERM
+
+	select {
+	case sig := <-raised:
+		if sig != syscall.SIGTERM {
+			t.Errorf("Expected SIGTERM re-raised, got %v", sig)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the signal to be re-raised")
+	}
+	if restored != s.oldState {
+		t.Errorf("Expected the saved terminal state to be restored")
+	}
+	if !strings.Contains(out.String(), "\x1b[?25h") {
+		t.Errorf("Expected the cursor to be shown, got %q", out.String())
+	}
+}
+
+func TestResizeBurstIsDebounced(t *testing.T) {
+	// Each quiet period ends when the test says so
+	periods := make(chan chan time.Time)
+	defer func(f func(time.Duration) <-chan time.Time) { after = f }(after)
+	after = func(d time.Duration) <-chan time.Time {
+		c := make(chan time.Time, 1)
+		periods <- c
+		return c
+	}
+
+	s := newHeadlessScreen(10, 2, io.Discard)
+	s.doneChan = make(chan struct{})
+	s.resizeCh = make(chan os.Signal, 1)
+	defer close(s.doneChan)
+
+	var mu sync.Mutex
+	width := 10
+	var resizes [][2]int
+	defer func(f func() (int, int, error)) { terminalSize = f }(terminalSize)
+	terminalSize = func() (int, int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return width, 3, nil
+	}
+	resized := make(chan struct{}, 5)
+	s.OnResize = func(w, h int) {
+		mu.Lock()
+		defer mu.Unlock()
+		resizes = append(resizes, [2]int{w, h})
+		resized <- struct{}{}
+	}
+	go s.handleResize()
+
+	// A drag: several signals, each inside the quiet period of the last
+	var last []chan time.Time
+	for i := 0; i < 5; i++ {
+		mu.Lock()
+		width = 11 + i
+		mu.Unlock()
+		s.resizeCh <- os.Interrupt // Any value: handleResize only notes that one arrived
+		last = append(last, <-periods)
+	}
+
+	// Periods cut short by a later signal end without a resize; the last
+	// one resizes once
+	for _, c := range last {
+		c <- time.Now()
+	}
+	select {
+	case <-resized:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a resize once the last quiet period ended")
+	}
+
+	// Once handleResize takes another signal, a second resize from the
+	// earlier periods would already have been made
+	s.resizeCh <- os.Interrupt
+	<-periods
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(resizes) != 1 || resizes[0] != [2]int{15, 3} {
+		t.Errorf("Expected a single resize to the final 15x3, got %v", resizes)
+	}
+}
+
+func TestFrameBridgesShortGaps(t *testing.T) {
+	var out strings.Builder
+	s := newHeadlessScreen(20, 2, &out)
+	s.Frame(func() { s.drawTextUnlocked(0, 0, "abcde            xyz", basement.Style{}) })
+
+	// Both ends change: one cursor move, the unchanged "bcd" rewritten
+	out.Reset()
+	s.Frame(func() { s.drawTextUnlocked(0, 0, "XbcdX            xyz", basement.Style{}) })
+	if got := out.String(); got != "\x1b[1;1HXbcdX\x1b[0m" {
+		t.Errorf("Expected the short gap rewritten, got %q", got)
+	}
+
+	// A long gap is still skipped with a cursor move
+	out.Reset()
+	s.Frame(func() { s.drawTextUnlocked(0, 0, "xbcdX            xyZ", basement.Style{}) })
+	if got := out.String(); got != "\x1b[1;1Hx\x1b[1;20HZ\x1b[0m" {
+		t.Errorf("Expected a cursor move over the long gap, got %q", got)
+	}
+
+	// Differently styled gaps aren't rewritten
+	s.Frame(func() {
+		s.drawTextUnlocked(0, 1, "a", basement.Style{})
+		s.drawTextUnlocked(1, 1, "b", basement.Style{Bold: true})
+		s.drawTextUnlocked(2, 1, "c", basement.Style{})
+	})
+	out.Reset()
+	s.Frame(func() {
+		s.drawTextUnlocked(0, 1, "A", basement.Style{})
+		s.drawTextUnlocked(1, 1, "b", basement.Style{Bold: true})
+		s.drawTextUnlocked(2, 1, "C", basement.Style{})
+	})
+	if got := out.String(); got != "\x1b[2;1HA\x1b[2;3HC\x1b[0m" {
+		t.Errorf("Expected no style change to bridge the gap, got %q", got)
+	}
+	for i := range s.Back.Cells {
+		if s.Front.Cells[i] != s.Back.Cells[i] {
+			t.Fatalf("Front buffer differs from back buffer at cell %d", i)
+		}
+	}
+}
+
+func TestRunStopsWhenHandlerReturnsTrue(t *testing.T) {
+	var out bytes.Buffer
+	s := newHeadlessScreen(10, 2, &out)
+	input := make(chan KeyEvent)
+	s.inputChan = input
+
+	var seen []rune
+	done := make(chan struct{})
+	go func() {
+		s.Run(func(ev KeyEvent) bool {
+			seen = append(seen, ev.Rune)
+			return ev.Rune == 'q'
+		})
+		close(done)
+	}()
+
+	input <- KeyEvent{Key: KeyChar, Rune: 'a'}
+	input <- KeyEvent{Key: KeyChar, Rune: 'q'}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Run to return after the handler returned true")
+	}
+
+	if string(seen) != "aq" {
+		t.Errorf("Expected both keys handled, got %q", string(seen))
+	}
+	if !strings.Contains(out.String(), "\x1b[?25h") {
+		t.Errorf("Expected Run to close the screen, got %q", out.String())
+	}
+}
+
+func BenchmarkFrameSmallChange(b *testing.B) {
+	s := newHeadlessScreen(200, 60, io.Discard)
+	for i := 0; i < b.N; i++ {
+		s.Frame(func() {
+			s.drawTextUnlocked(0, 0, strconv.Itoa(i), basement.Style{})
+		})
+	}
+}
+
+var longLine = strings.Repeat("The quick brown fox jumps over the lazy dog. ", 4)
+
+func BenchmarkFrameScatteredChanges(b *testing.B) {
+	s := newHeadlessScreen(200, 60, io.Discard)
+	rows := [2]string{strings.Repeat("ab", 100), strings.Repeat("aB", 100)}
+	for i := 0; i < b.N; i++ {
+		s.Frame(func() {
+			for y := 0; y < 60; y++ {
+				s.drawTextUnlocked(0, y, rows[i%2], basement.Style{})
+			}
+		})
+	}
+}
+
+func BenchmarkBufferSetPerRune(b *testing.B) {
+	buf := NewBuffer(200, 1)
+	style := basement.Style{Bold: true}
+	for i := 0; i < b.N; i++ {
+		col := 0
+		for _, r := range longLine {
+			buf.Set(col, 0, r, style)
+			col++
+		}
+	}
+}
+
+func BenchmarkBufferSetString(b *testing.B) {
+	buf := NewBuffer(200, 1)
+	style := basement.Style{Bold: true}
+	for i := 0; i < b.N; i++ {
+		buf.SetString(0, 0, longLine, style)
+	}
+}
+
+func TestSelectionFlushesReversed(t *testing.T) {
+	var out bytes.Buffer
+	s := newHeadlessScreen(6, 3, &out)
+	bold := basement.Style{Bold: true}
+	s.DrawText(0, 0, "aaaaaa", bold)
+	s.DrawText(0, 1, "bbbbbb", bold)
+	s.DrawText(0, 2, "cccccc", bold)
+	s.Render()
+
+	out.Reset()
+	s.SetSelection(4, 1, 1, 0)
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 6; x++ {
+			want := y == 0 && x >= 1 || y == 1 && x <= 4
+			if got := s.Front.Get(x, y).Style; got.Reverse != want || !got.Bold {
+				t.Errorf("(%d,%d): expected reverse %v over bold, got %+v", x, y, want, got)
+			}
+			if s.Back.Get(x, y).Style != bold {
+				t.Errorf("(%d,%d): expected the drawn style left alone", x, y)
+			}
+		}
+	}
+	if !strings.Contains(out.String(), "\x1b[7m") {
+		t.Errorf("Expected reverse video in the output, got %q", out.String())
+	}
+
+	s.ClearSelection()
+	for x := 0; x < 6; x++ {
+		if s.Front.Get(x, 0).Style != bold || s.Front.Get(x, 1).Style != bold {
+			t.Fatalf("Expected the selection cleared at column %d", x)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package tui
+
+import "basement/signals"
+
+// ScrollKey applies a scrolling key to offset, the first visible row of
+// content rows shown viewport rows at a time: arrows move one row, PgUp and
+// PgDown a viewport, Home and End jump to the top and bottom. The offset is
+// clamped so the view never runs past the content. It reports whether ev was
+// a scrolling key.
+func ScrollKey(offset *signals.Signal[int], ev KeyEvent, viewport, content int) bool {
+	y := offset.Peek()
+	switch ev.Key {
+	case KeyArrowUp:
+		y--
+	case KeyArrowDown:
+		y++
+	case KeyPgUp:
+		y -= viewport
+	case KeyPgDown:
+		y += viewport
+	case KeyHome:
+		y = 0
+	case KeyEnd:
+		y = content
+	default:
+		return false
+	}
+	offset.Set(clampScroll(y, viewport, content))
+	return true
+}
+
+// clampScroll keeps y between the top and the last full viewport of content
+func clampScroll(y, viewport, content int) int {
+	if max := content - viewport; y > max {
+		y = max
+	}
+	if y < 0 {
+		y = 0
+	}
+	return y
+}
+
+// ScrollKey is ScrollKey for the screen's vertical scroll, with the screen
+// height as the viewport
+func (s *Screen) ScrollKey(ev KeyEvent, content int) bool {
+	return ScrollKey(s.ScrollY, ev, s.height.Peek(), content)
+}
+
+// ContentHeight returns how many rows r takes when drawn width columns wide,
+// e.g. the content size to pass to ScrollKey. A layout that stretches to fill
+// its space, like one with Flex rows, counts as maxHeadlessHeight rows.
+func ContentHeight(r Renderable, width int) int {
+	return renderHeadless(r, width, 0).Back.Height
+}
@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"basement/signals"
+	"testing"
+)
+
+func TestScrollKeyPages(t *testing.T) {
+	offset := signals.New(0)
+	pgDown := KeyEvent{Key: KeyPgDown}
+
+	// 25 rows of content in a 10-row viewport: the last page starts at 15
+	ScrollKey(offset, pgDown, 10, 25)
+	if got := offset.Get(); got != 10 {
+		t.Errorf("Expected PgDown to advance one viewport to 10, got %d", got)
+	}
+	ScrollKey(offset, pgDown, 10, 25)
+	if got := offset.Get(); got != 15 {
+		t.Errorf("Expected PgDown to clamp at the bottom (15), got %d", got)
+	}
+
+	ScrollKey(offset, KeyEvent{Key: KeyPgUp}, 10, 25)
+	if got := offset.Get(); got != 5 {
+		t.Errorf("Expected PgUp to go back one viewport to 5, got %d", got)
+	}
+	ScrollKey(offset, KeyEvent{Key: KeyEnd}, 10, 25)
+	if got := offset.Get(); got != 15 {
+		t.Errorf("Expected End to jump to 15, got %d", got)
+	}
+	ScrollKey(offset, KeyEvent{Key: KeyHome}, 10, 25)
+	if got := offset.Get(); got != 0 {
+		t.Errorf("Expected Home to jump to 0, got %d", got)
+	}
+
+	// Content shorter than the viewport never scrolls
+	ScrollKey(offset, pgDown, 10, 4)
+	if got := offset.Get(); got != 0 {
+		t.Errorf("Expected no scrolling for short content, got %d", got)
+	}
+
+	if ScrollKey(offset, KeyEvent{Key: KeyChar, Rune: 'x'}, 10, 25) {
+		t.Errorf("Expected other keys to be ignored")
+	}
+}
+
+func TestContentHeight(t *testing.T) {
+	if got := ContentHeight(Template("# Title\n- one\n- two"), 20); got != 3 {
+		t.Errorf("Expected 3 rows, got %d", got)
+	}
+}
@@ -0,0 +1,69 @@
+package tui
+
+// selection is a run of screen cells from (x1, y1) to (x2, y2) inclusive,
+// read left to right and top to bottom as a terminal selects text
+type selection struct {
+	x1, y1, x2, y2 int
+}
+
+// contains reports whether the cell at x, y is selected
+func (sel *selection) contains(x, y int) bool {
+	if y < sel.y1 || y > sel.y2 {
+		return false
+	}
+	if y == sel.y1 && x < sel.x1 || y == sel.y2 && x > sel.x2 {
+		return false
+	}
+	return true
+}
+
+// SetSelection highlights the cells from (x1, y1) through (x2, y2) in reverse
+// video: the first row from x1, the last up to x2, the rows between in full.
+// SetSelection(0, y1, width-1, y2) selects whole lines. The highlight is
+// applied as the screen is flushed, so the cells and their styles are left as
+// drawn. The ends may be given in either order.
+func (s *Screen) SetSelection(x1, y1, x2, y2 int) {
+	if y2 < y1 || y2 == y1 && x2 < x1 {
+		x1, y1, x2, y2 = x2, y2, x1, y1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invalidateSelection()
+	s.selection = &selection{x1: x1, y1: y1, x2: x2, y2: y2}
+	s.invalidateSelection()
+	s.renderUnlocked()
+}
+
+// ClearSelection removes the highlight set by SetSelection
+func (s *Screen) ClearSelection() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.selection == nil {
+		return
+	}
+	s.invalidateSelection()
+	s.selection = nil
+	s.renderUnlocked()
+}
+
+// invalidateSelection marks the selected rows for re-diffing, so the next
+// flush redraws them with or without the highlight
+func (s *Screen) invalidateSelection() {
+	sel := s.selection
+	if sel == nil {
+		return
+	}
+	for y := sel.y1; y <= sel.y2 && y < s.Back.Height; y++ {
+		if y >= 0 {
+			s.Back.dirty[y].add(0, s.Back.Width-1)
+		}
+	}
+}
+
+// selected returns c as it is flushed at x, y: reversed inside the selection
+func (s *Screen) selected(c Cell, x, y int) Cell {
+	if s.selection != nil && s.selection.contains(x, y) {
+		c.Style.Reverse = !c.Style.Reverse
+	}
+	return c
+}
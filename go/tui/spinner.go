@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"basement/signals"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultSpinnerFrames is the braille animation used by NewSpinner
+var DefaultSpinnerFrames = []string{"⣾", "⣽", "⣻", "⢿", "⡿", "⣟", "⣯", "⣷"}
+
+// Spinner is an animated indicator that advances one frame per tick.
+// It implements signals.Getter, so Template("%v", spinner) re-renders on every frame.
+type Spinner struct {
+	frames   []string
+	interval time.Duration
+	frame    *signals.Signal[int]
+
+	mu      sync.Mutex
+	stop    chan struct{} // Closed to end the ticker loop
+	done    chan struct{} // Closed once the ticker loop has exited
+	ticking *int32        // 1 while the ticker loop is emitting a frame
+}
+
+// NewSpinner creates a stopped spinner with the default frames and an 80ms interval
+func NewSpinner() *Spinner {
+	return &Spinner{
+		frames:   DefaultSpinnerFrames,
+		interval: 80 * time.Millisecond,
+		frame:    signals.New(0),
+	}
+}
+
+// WithFrames sets the animation frames
+func (s *Spinner) WithFrames(frames ...string) *Spinner {
+	s.frames = frames
+	return s
+}
+
+// WithInterval sets the time between frames
+func (s *Spinner) WithInterval(d time.Duration) *Spinner {
+	s.interval = d
+	return s
+}
+
+// GetValue implements the Getter interface, returning the current frame
+func (s *Spinner) GetValue() interface{} {
+	if len(s.frames) == 0 {
+		return ""
+	}
+	return s.frames[s.frame.Get()%len(s.frames)]
+}
+
+// Start begins animating. Calling Start on a running spinner does nothing.
+func (s *Spinner) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	s.ticking = new(int32)
+
+	ticker := time.NewTicker(s.interval)
+	go func(stop, done chan struct{}, ticking *int32) {
+		defer close(done)
+		defer ticker.Stop()
+		s.run(ticker.C, stop, ticking)
+	}(s.stop, s.done, s.ticking)
+}
+
+// Stop halts the animation and releases the ticker.
+// It returns once no further frames can be emitted. It may be called while a
+// frame is drawn, e.g. from an effect rendering the spinner: that frame
+// finishes, but Stop doesn't wait for it.
+func (s *Spinner) Stop() {
+	s.mu.Lock()
+	stop, done, ticking := s.stop, s.done, s.ticking
+	s.stop, s.done, s.ticking = nil, nil, nil
+	s.mu.Unlock()
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	// Unless a frame is being emitted (maybe by the caller itself), the
+	// ticker loop is about to see stop and exit; after a frame it checks stop
+	// before waiting for the next tick
+	if atomic.LoadInt32(ticking) == 0 {
+		<-done
+	}
+}
+
+// run advances the frame on every tick until stop is closed, setting ticking
+// while it does
+func (s *Spinner) run(ticks <-chan time.Time, stop <-chan struct{}, ticking *int32) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticks:
+			atomic.StoreInt32(ticking, 1)
+			s.advance()
+			atomic.StoreInt32(ticking, 0)
+		}
+		// A Stop during that frame didn't wait, so it must win over a tick
+		select {
+		case <-stop:
+			return
+		default:
+		}
+	}
+}
+
+// advance moves to the next frame, notifying anything rendering the spinner
+func (s *Spinner) advance() {
+	s.frame.Set(s.frame.Peek() + 1)
+}
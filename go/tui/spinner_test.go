@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"basement/signals"
+	"testing"
+	"time"
+)
+
+func TestSpinnerCyclesFrames(t *testing.T) {
+	s := NewSpinner().WithFrames("a", "b", "c")
+
+	want := []string{"a", "b", "c", "a"}
+	for i, w := range want {
+		if got := s.GetValue(); got != w {
+			t.Errorf("Frame %d: expected %q, got %q", i, w, got)
+		}
+		s.advance()
+	}
+
+	// Drive the ticker loop with a fake clock
+	ticks := make(chan time.Time)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		s.run(ticks, stop, new(int32))
+		close(done)
+	}()
+	for i := 0; i < 2; i++ {
+		ticks <- time.Now()
+	}
+	close(stop)
+	<-done
+
+	// 4 advances + 2 ticks = frame 6 -> "a"
+	if got := s.GetValue(); got != "a" {
+		t.Errorf("Expected frame \"a\" after ticking, got %q", got)
+	}
+}
+
+func TestSpinnerStop(t *testing.T) {
+	s := NewSpinner().WithInterval(time.Millisecond)
+	s.Start()
+	s.Start() // no-op while running
+	s.Stop()
+
+	frame := s.frame.Peek()
+	time.Sleep(10 * time.Millisecond)
+	if got := s.frame.Peek(); got != frame {
+		t.Errorf("Expected no frames after Stop, went from %d to %d", frame, got)
+	}
+}
+
+func TestSpinnerStopFromFrame(t *testing.T) {
+	s := NewSpinner().WithInterval(time.Millisecond)
+	stopped := make(chan struct{})
+	signals.CreateEffect(func() {
+		// Runs on the ticker goroutine for every frame after the first
+		if s.frame.Get() == 1 {
+			s.Stop()
+			close(stopped)
+		}
+	})
+	s.Start()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Stop inside a frame to return")
+	}
+	if got := s.frame.Peek(); got != 1 {
+		t.Errorf("Expected no frames after Stop, got frame %d", got)
+	}
+}
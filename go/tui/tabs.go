@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"basement/basement"
+	"basement/signals"
+)
+
+// Tab is one page of a Tabs component
+type Tab struct {
+	Title   string
+	Content *LayoutNode
+}
+
+// Tabs shows a tab bar above the content of the active tab.
+// Only the active page is measured and drawn. It implements signals.Getter,
+// resolving to its *LayoutNode view, so it re-renders when the active tab changes.
+type Tabs struct {
+	tabs   []Tab
+	active *signals.Signal[int]
+	views  []*LayoutNode // One per active index, built on first use
+}
+
+// NewTabs creates a tab set with the first tab active
+func NewTabs(tabs ...Tab) *Tabs {
+	return &Tabs{
+		tabs:   tabs,
+		active: signals.New(0),
+		views:  make([]*LayoutNode, len(tabs)),
+	}
+}
+
+// Active returns the signal holding the active tab index
+func (t *Tabs) Active() *signals.Signal[int] {
+	return t.active
+}
+
+// SetActive switches to tab i; out-of-range indices are ignored
+func (t *Tabs) SetActive(i int) {
+	if i < 0 || i >= len(t.tabs) {
+		return
+	}
+	t.active.Set(i)
+}
+
+// HandleKey switches tabs with Left/Right (wrapping) or the number keys 1-9.
+// Returns true if the key was used.
+func (t *Tabs) HandleKey(ev KeyEvent) bool {
+	n := len(t.tabs)
+	if n == 0 {
+		return false
+	}
+
+	switch {
+	case ev.Key == KeyArrowLeft:
+		t.SetActive((t.active.Peek() - 1 + n) % n)
+	case ev.Key == KeyArrowRight:
+		t.SetActive((t.active.Peek() + 1) % n)
+	case ev.Key == KeyChar && ev.Mod == ModNone && ev.Rune >= '1' && ev.Rune <= '9':
+		idx := int(ev.Rune - '1')
+		if idx >= n {
+			return false
+		}
+		t.SetActive(idx)
+	default:
+		return false
+	}
+	return true
+}
+
+// View returns the tab bar and the active page as a column. Measure and Draw
+// both resolve the tabs, so the column for each active index is built once
+// and handed out from then on.
+func (t *Tabs) View() *LayoutNode {
+	idx := t.active.Get()
+	if idx >= 0 && idx < len(t.views) && t.views[idx] != nil {
+		return t.views[idx]
+	}
+
+	// Tab bar: titles separated by spaces, the active one reversed
+	block := basement.NewNode(basement.NodeBlock)
+	for i, tab := range t.tabs {
+		if i > 0 {
+			block.AddChild(&basement.Node{Type: basement.NodeText, Content: " "})
+		}
+		style := basement.Style{Dim: true}
+		if i == idx {
+			style = basement.Style{Bold: true, Reverse: true}
+		}
+		block.AddChild(&basement.Node{Type: basement.NodeText, Content: " " + tab.Title + " ", Style: style})
+	}
+	bar := basement.NewNode(basement.NodeRoot)
+	bar.AddChild(block)
+
+	view := Col(&LayoutNode{Width: Auto(), Height: Auto(), Content: bar})
+	if idx < 0 || idx >= len(t.tabs) {
+		return view
+	}
+	if page := t.tabs[idx].Content; page != nil {
+		// Held as content rather than linked in as a child, so the caller's
+		// node keeps its own place in the tree
+		view.addChild(&LayoutNode{Width: Auto(), Height: Auto(), Content: page})
+	}
+	t.views[idx] = view
+	return view
+}
+
+// GetValue implements the Getter interface
+func (t *Tabs) GetValue() interface{} {
+	return t.View()
+}
@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"io"
+	"testing"
+)
+
+func TestTabsDrawOnlyActivePage(t *testing.T) {
+	tabs := NewTabs(
+		Tab{Title: "One", Content: Box("first page", false, 0)},
+		Tab{Title: "Two", Content: Box("second page", false, 0)},
+	)
+
+	got := RenderToString(Template("%v", tabs), 20, 2)
+	if want := " One   Two \nfirst page\n"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	tabs.SetActive(1)
+	got = RenderToString(Template("%v", tabs), 20, 2)
+	if want := " One   Two \nsecond page\n"; got != want {
+		t.Errorf("Expected %q after switching, got %q", want, got)
+	}
+}
+
+func TestTabsHandleKey(t *testing.T) {
+	tabs := NewTabs(Tab{Title: "A"}, Tab{Title: "B"}, Tab{Title: "C"})
+
+	tabs.HandleKey(KeyEvent{Key: KeyArrowLeft})
+	if got := tabs.Active().Get(); got != 2 {
+		t.Errorf("Expected Left from the first tab to wrap to 2, got %d", got)
+	}
+
+	tabs.HandleKey(KeyEvent{Key: KeyChar, Rune: '2'})
+	if got := tabs.Active().Get(); got != 1 {
+		t.Errorf("Expected '2' to select index 1, got %d", got)
+	}
+
+	if tabs.HandleKey(KeyEvent{Key: KeyChar, Rune: '9'}) {
+		t.Errorf("Expected '9' to be ignored with only 3 tabs")
+	}
+}
+
+func TestTabsInsideLayout(t *testing.T) {
+	first, second := Text("first page"), Text("second page")
+	tabs := NewTabs(Tab{Title: "One", Content: first}, Tab{Title: "Two", Content: second})
+
+	s := newHeadlessScreen(20, 4, io.Discard)
+	Render(s, func() Renderable { return Template("%v", Col("top", tabs)) })
+	if got := s.Back.String(); got != "top\n One   Two \nfirst page\n\n" {
+		t.Fatalf("Expected the first page drawn in the column, got %q", got)
+	}
+
+	tabs.SetActive(1)
+	if got := s.Back.String(); got != "top\n One   Two \nsecond page\n\n" {
+		t.Errorf("Expected the second page drawn after switching, got %q", got)
+	}
+	if first.Parent != nil || second.Parent != nil {
+		t.Errorf("Expected the pages left unlinked from the tab view")
+	}
+}
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"os"
+	"strings"
 
 	"golang.org/x/term"
 )
@@ -25,3 +26,50 @@ func disableRawMode(f *os.File, s *State) error {
 	}
 	return term.Restore(int(f.Fd()), s.state)
 }
+
+// italicTerms are TERM families (the part before the first "-") whose
+// terminals draw italic and strikethrough
+var italicTerms = map[string]bool{
+	"xterm":     true, // Also xterm-kitty, xterm-ghostty, and most emulators
+	"alacritty": true,
+	"foot":      true,
+	"wezterm":   true,
+	"tmux":      true,
+	"contour":   true,
+	"mintty":    true,
+}
+
+// detectCaps reports whether the terminal described by the environment can
+// draw italic and strikethrough text. Emulators that announce themselves in
+// COLORTERM or their own variables are trusted before TERM, since TERM is
+// often a generic name. The Linux console, dumb terminals and GNU screen
+// (outside tmux) get neither.
+func detectCaps(getenv func(string) string) (italic, strike bool) {
+	termEnv := getenv("TERM")
+	if termEnv == "" || termEnv == "dumb" || termEnv == "linux" {
+		return false, false
+	}
+
+	switch getenv("COLORTERM") {
+	case "truecolor", "24bit":
+		return true, true
+	}
+	for _, v := range []string{"VTE_VERSION", "KITTY_WINDOW_ID", "WT_SESSION", "KONSOLE_VERSION", "WEZTERM_EXECUTABLE"} {
+		if getenv(v) != "" {
+			return true, true
+		}
+	}
+
+	family := termEnv
+	if i := strings.IndexByte(family, '-'); i >= 0 {
+		family = family[:i]
+	}
+	if italicTerms[family] {
+		return true, true
+	}
+	// tmux commonly runs with TERM=screen-256color but draws both
+	if family == "screen" && getenv("TMUX") != "" {
+		return true, true
+	}
+	return false, false
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package tui
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// enableVirtualTerminal is a no-op: Unix terminals understand escape
+// sequences as they are, so there is nothing to restore
+func enableVirtualTerminal() (restore func() error, err error) { return nil, nil }
+
+// notifyResize delivers a value on ch whenever the terminal is resized
+func notifyResize(ch chan os.Signal, done <-chan struct{}) {
+	signal.Notify(ch, syscall.SIGWINCH)
+}
+
+// stopResize undoes notifyResize
+func stopResize(ch chan os.Signal) {
+	signal.Stop(ch)
+}
+
+// raiseSignal sends sig to the current process
+func raiseSignal(sig os.Signal) {
+	syscall.Kill(syscall.Getpid(), sig.(syscall.Signal))
+}
@@ -0,0 +1,88 @@
+//go:build windows
+
+package tui
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// enableVirtualTerminal switches the console to VT mode: output escape
+// sequences are interpreted rather than printed, and keys arrive on stdin as
+// the same sequences a Unix terminal sends, so inputLoop decodes them as is.
+// The returned func puts back the modes the console had before.
+func enableVirtualTerminal() (restore func() error, err error) {
+	out := windows.Handle(os.Stdout.Fd())
+	var outMode uint32
+	if err := windows.GetConsoleMode(out, &outMode); err != nil {
+		return nil, err
+	}
+	if err := windows.SetConsoleMode(out, outMode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+		return nil, err
+	}
+
+	in := windows.Handle(os.Stdin.Fd())
+	var inMode uint32
+	if err := windows.GetConsoleMode(in, &inMode); err != nil {
+		windows.SetConsoleMode(out, outMode)
+		return nil, err
+	}
+	if err := windows.SetConsoleMode(in, inMode|windows.ENABLE_VIRTUAL_TERMINAL_INPUT); err != nil {
+		windows.SetConsoleMode(out, outMode)
+		return nil, err
+	}
+
+	return func() error {
+		errOut := windows.SetConsoleMode(out, outMode)
+		if err := windows.SetConsoleMode(in, inMode); err != nil {
+			return err
+		}
+		return errOut
+	}, nil
+}
+
+// resizeSignal stands in for SIGWINCH, which Windows doesn't have
+type resizeSignal struct{}
+
+func (resizeSignal) String() string { return "resize" }
+func (resizeSignal) Signal()        {}
+
+// resizePoll is how often the console size is checked for changes
+const resizePoll = 250 * time.Millisecond
+
+// notifyResize delivers a value on ch whenever the console is resized, by
+// polling its size until done is closed
+func notifyResize(ch chan os.Signal, done <-chan struct{}) {
+	go func() {
+		w, h, _ := terminalSize()
+		ticker := time.NewTicker(resizePoll)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				nw, nh, err := terminalSize()
+				if err != nil || nw == w && nh == h {
+					continue
+				}
+				w, h = nw, nh
+				select {
+				case ch <- resizeSignal{}:
+				default:
+				}
+			}
+		}
+	}()
+}
+
+// stopResize is a no-op: the poller stops when the screen closes
+func stopResize(ch chan os.Signal) {}
+
+// raiseSignal exits the way an interrupted console program does; Windows
+// can't deliver signals to the current process
+func raiseSignal(sig os.Signal) {
+	os.Exit(0xC000013A & 0x7fffffff) // STATUS_CONTROL_C_EXIT
+}
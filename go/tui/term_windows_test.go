@@ -0,0 +1,52 @@
+//go:build windows
+
+package tui
+
+import (
+	"os"
+	"testing"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/term"
+)
+
+func TestEnableVirtualTerminal(t *testing.T) {
+	if !term.IsTerminal(int(os.Stdout.Fd())) || !term.IsTerminal(int(os.Stdin.Fd())) {
+		t.Skip("Not attached to a console")
+	}
+	var outBefore, inBefore uint32
+	if err := windows.GetConsoleMode(windows.Handle(os.Stdout.Fd()), &outBefore); err != nil {
+		t.Fatal(err)
+	}
+	if err := windows.GetConsoleMode(windows.Handle(os.Stdin.Fd()), &inBefore); err != nil {
+		t.Fatal(err)
+	}
+	restore, err := enableVirtualTerminal()
+	if err != nil {
+		t.Fatalf("Expected VT mode to be enabled, got %v", err)
+	}
+
+	var mode uint32
+	if err := windows.GetConsoleMode(windows.Handle(os.Stdout.Fd()), &mode); err != nil {
+		t.Fatal(err)
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING == 0 {
+		t.Errorf("Expected VT processing on stdout, got mode %#x", mode)
+	}
+	if err := windows.GetConsoleMode(windows.Handle(os.Stdin.Fd()), &mode); err != nil {
+		t.Fatal(err)
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_INPUT == 0 {
+		t.Errorf("Expected VT input on stdin, got mode %#x", mode)
+	}
+
+	if err := restore(); err != nil {
+		t.Fatalf("Expected the console modes restored, got %v", err)
+	}
+	if err := windows.GetConsoleMode(windows.Handle(os.Stdout.Fd()), &mode); err != nil || mode != outBefore {
+		t.Errorf("Expected stdout mode %#x back, got %#x (%v)", outBefore, mode, err)
+	}
+	if err := windows.GetConsoleMode(windows.Handle(os.Stdin.Fd()), &mode); err != nil || mode != inBefore {
+		t.Errorf("Expected stdin mode %#x back, got %#x (%v)", inBefore, mode, err)
+	}
+}
@@ -0,0 +1,85 @@
+package tui
+
+// TextBuffer is an editable line of text with a cursor.
+// It works on runes, so multi-byte characters are inserted and deleted whole.
+type TextBuffer struct {
+	runes  []rune
+	cursor int // Insertion point, 0..len(runes)
+}
+
+// NewTextBuffer creates a buffer holding s with the cursor at the end
+func NewTextBuffer(s string) *TextBuffer {
+	r := []rune(s)
+	return &TextBuffer{runes: r, cursor: len(r)}
+}
+
+// String returns the text
+func (b *TextBuffer) String() string {
+	return string(b.runes)
+}
+
+// Len returns the length of the text in runes
+func (b *TextBuffer) Len() int {
+	return len(b.runes)
+}
+
+// Cursor returns the cursor position in runes
+func (b *TextBuffer) Cursor() int {
+	return b.cursor
+}
+
+// SetCursor moves the cursor to pos, clamped to the text
+func (b *TextBuffer) SetCursor(pos int) {
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > len(b.runes) {
+		pos = len(b.runes)
+	}
+	b.cursor = pos
+}
+
+// Insert adds r at the cursor and moves the cursor past it
+func (b *TextBuffer) Insert(r rune) {
+	b.runes = append(b.runes, 0)
+	copy(b.runes[b.cursor+1:], b.runes[b.cursor:])
+	b.runes[b.cursor] = r
+	b.cursor++
+}
+
+// Backspace deletes the rune before the cursor
+func (b *TextBuffer) Backspace() {
+	if b.cursor == 0 {
+		return
+	}
+	b.runes = append(b.runes[:b.cursor-1], b.runes[b.cursor:]...)
+	b.cursor--
+}
+
+// Delete deletes the rune under the cursor
+func (b *TextBuffer) Delete() {
+	if b.cursor >= len(b.runes) {
+		return
+	}
+	b.runes = append(b.runes[:b.cursor], b.runes[b.cursor+1:]...)
+}
+
+// Left moves the cursor one rune back
+func (b *TextBuffer) Left() {
+	b.SetCursor(b.cursor - 1)
+}
+
+// Right moves the cursor one rune forward
+func (b *TextBuffer) Right() {
+	b.SetCursor(b.cursor + 1)
+}
+
+// Home moves the cursor to the start
+func (b *TextBuffer) Home() {
+	b.cursor = 0
+}
+
+// End moves the cursor to the end
+func (b *TextBuffer) End() {
+	b.cursor = len(b.runes)
+}
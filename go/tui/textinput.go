@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"basement/basement"
+	"basement/signals"
+	"unicode/utf8"
+)
+
+// TextInput is a single-line text field with a cursor.
+// It implements signals.Getter, resolving to its *LayoutNode view, so it can be
+// placed straight into a Template hole and re-renders as the user types.
+type TextInput struct {
+	buf         *TextBuffer
+	value       *signals.Signal[string]
+	cursor      *signals.Signal[int]
+	placeholder string
+
+	// The view built for viewText and viewPos, handed out to both Measure
+	// and Draw
+	view     *LayoutNode
+	viewText string
+	viewPos  int
+}
+
+// NewTextInput creates an empty text field
+func NewTextInput() *TextInput {
+	return &TextInput{
+		buf:    NewTextBuffer(""),
+		value:  signals.New(""),
+		cursor: signals.New(0),
+	}
+}
+
+// WithPlaceholder sets the hint shown while the field is empty
+func (t *TextInput) WithPlaceholder(text string) *TextInput {
+	t.placeholder = text
+	t.view = nil
+	return t
+}
+
+// Value returns the signal holding the field's text.
+// Setting it replaces the text and moves the cursor to the end.
+func (t *TextInput) Value() *signals.Signal[string] {
+	return t.value
+}
+
+// Cursor returns the cursor position in runes
+func (t *TextInput) Cursor() int {
+	return t.cursorIn(t.value.Peek(), t.cursor.Peek())
+}
+
+// cursorIn returns where the cursor is in text: pos while text is what the
+// field last edited, the end once the value has been set from outside
+func (t *TextInput) cursorIn(text string, pos int) int {
+	if text != t.buf.String() {
+		return utf8.RuneCountInString(text)
+	}
+	return pos
+}
+
+// HandleKey applies an editing key to the field. Returns true if the key was used.
+func (t *TextInput) HandleKey(ev KeyEvent) bool {
+	// Pick up text set from outside since the last edit
+	if v := t.value.Peek(); v != t.buf.String() {
+		t.buf = NewTextBuffer(v)
+	}
+
+	switch ev.Key {
+	case KeyChar:
+		if ev.Mod&(ModCtrl|ModAlt) != 0 {
+			return false
+		}
+		t.buf.Insert(ev.Rune)
+	case KeyBackspace:
+		t.buf.Backspace()
+	case KeyDelete:
+		t.buf.Delete()
+	case KeyArrowLeft:
+		t.buf.Left()
+	case KeyArrowRight:
+		t.buf.Right()
+	case KeyHome:
+		t.buf.Home()
+	case KeyEnd:
+		t.buf.End()
+	default:
+		return false
+	}
+
+	// One update, so the view never shows the new text with the old cursor
+	signals.Batch(func() {
+		t.value.Set(t.buf.String())
+		t.cursor.Set(t.buf.Cursor())
+	})
+	return true
+}
+
+// View builds the field: the text with the cell under the cursor reversed,
+// or the dimmed placeholder when empty. The node is reused until the text or
+// cursor changes.
+func (t *TextInput) View() *LayoutNode {
+	value := t.value.Get()
+	pos := t.cursorIn(value, t.cursor.Get())
+	text := []rune(value)
+	if pos > len(text) {
+		pos = len(text)
+	}
+	if t.view != nil && value == t.viewText && pos == t.viewPos {
+		return t.view
+	}
+
+	block := basement.NewNode(basement.NodeBlock)
+	if pos > 0 {
+		block.AddChild(&basement.Node{Type: basement.NodeText, Content: string(text[:pos])})
+	}
+
+	under := " "
+	if pos < len(text) {
+		under = string(text[pos])
+	}
+	block.AddChild(&basement.Node{Type: basement.NodeText, Content: under, Style: basement.Style{Reverse: true}})
+
+	if pos+1 < len(text) {
+		block.AddChild(&basement.Node{Type: basement.NodeText, Content: string(text[pos+1:])})
+	}
+	if len(text) == 0 && t.placeholder != "" {
+		block.AddChild(&basement.Node{Type: basement.NodeText, Content: t.placeholder, Style: basement.Style{Dim: true}})
+	}
+
+	root := basement.NewNode(basement.NodeRoot)
+	root.AddChild(block)
+	t.view = &LayoutNode{Width: Auto(), Height: Auto(), Content: root}
+	t.viewText, t.viewPos = value, pos
+	return t.view
+}
+
+// GetValue implements the Getter interface
+func (t *TextInput) GetValue() interface{} {
+	return t.View()
+}
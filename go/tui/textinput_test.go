@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"io"
+	"testing"
+)
+
+func typeString(t *TextInput, s string) {
+	for _, r := range s {
+		t.HandleKey(KeyEvent{Key: KeyChar, Rune: r})
+	}
+}
+
+func TestTextInputEditing(t *testing.T) {
+	in := NewTextInput()
+	typeString(in, "héllo")
+
+	in.HandleKey(KeyEvent{Key: KeyHome})
+	in.HandleKey(KeyEvent{Key: KeyArrowRight})
+	if in.Cursor() != 1 {
+		t.Fatalf("Expected cursor at 1, got %d", in.Cursor())
+	}
+
+	// Insert mid-string
+	typeString(in, "XY")
+	if got := in.Value().Get(); got != "hXYéllo" {
+		t.Errorf("Expected mid-string insertion, got %q", got)
+	}
+
+	// Backspace and Delete around the cursor, rune-aware
+	in.HandleKey(KeyEvent{Key: KeyBackspace})
+	in.HandleKey(KeyEvent{Key: KeyDelete})
+	if got := in.Value().Get(); got != "hXllo" {
+		t.Errorf("Expected \"hXllo\", got %q", got)
+	}
+
+	in.HandleKey(KeyEvent{Key: KeyEnd})
+	in.HandleKey(KeyEvent{Key: KeyArrowRight})
+	if in.Cursor() != 5 {
+		t.Errorf("Expected cursor clamped at end (5), got %d", in.Cursor())
+	}
+
+	if in.HandleKey(KeyEvent{Key: KeyChar, Rune: 'c', Mod: ModCtrl}) {
+		t.Errorf("Expected Ctrl+C to be left to the caller")
+	}
+}
+
+func TestTextInputView(t *testing.T) {
+	in := NewTextInput().WithPlaceholder("name")
+	if got := RenderToString(Template("Name: %v", in), 20, 1); got != "Name:  name\n" {
+		t.Errorf("Expected cursor then placeholder, got %q", got)
+	}
+
+	typeString(in, "ab")
+	in.HandleKey(KeyEvent{Key: KeyArrowLeft})
+
+	s := newTestScreen(10, 1)
+	r := Template("%v", in)
+	renderNode(s, r.Root, r.Args, 0, 0)
+	if c := s.Back.Get(1, 0); c.Char != 'b' || !c.Style.Reverse {
+		t.Errorf("Expected reversed cursor over 'b', got %q (reverse=%v)", c.Char, c.Style.Reverse)
+	}
+}
+
+func TestTextInputInsideLayout(t *testing.T) {
+	in := NewTextInput()
+	s := newHeadlessScreen(10, 3, io.Discard)
+	frames := 0
+	Render(s, func() Renderable {
+		frames++
+		return Template("%v", Box(in, true, 0).WithWidth(Fixed(9)))
+	})
+	typeString(in, "hi")
+	if got := s.Back.String(); got != "┌───────┐\n│hi     │\n└───────┘\n" {
+		t.Fatalf("Expected the text drawn inside the box, got %q", got)
+	}
+	if c := s.Back.Get(3, 1); !c.Style.Reverse {
+		t.Errorf("Expected the cursor after the text, got %+v", c)
+	}
+
+	// One render per key, never the new text with the old cursor
+	frames = 0
+	in.HandleKey(KeyEvent{Key: KeyChar, Rune: '!'})
+	if frames != 1 {
+		t.Errorf("Expected one render for a key, got %d", frames)
+	}
+
+	// Setting the value moves the cursor to its end
+	in.HandleKey(KeyEvent{Key: KeyHome})
+	in.Value().Set("hello")
+	if in.Cursor() != 5 {
+		t.Errorf("Expected the cursor at the end of the new value, got %d", in.Cursor())
+	}
+	if c := s.Back.Get(6, 1); !c.Style.Reverse {
+		t.Errorf("Expected the cursor drawn after \"hello\", got %+v", c)
+	}
+	in.HandleKey(KeyEvent{Key: KeyBackspace})
+	if got := in.Value().Get(); got != "hell" {
+		t.Errorf("Expected editing to continue from the end, got %q", got)
+	}
+}
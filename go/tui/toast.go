@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"basement/basement"
+	"basement/signals"
+	"time"
+)
+
+// ToastStyle is how toasts are drawn
+var ToastStyle = basement.Style{Reverse: true}
+
+// Toast is a transient message drawn over the top-right corner of the screen
+// until its duration passes or it is dismissed
+type Toast struct {
+	Message string
+	visible *signals.Signal[bool]
+	timer   *time.Timer
+}
+
+// Visible returns the signal that turns false when the toast goes away
+func (t *Toast) Visible() *signals.Signal[bool] {
+	return t.visible
+}
+
+// Dismiss hides the toast before its duration is up
+func (t *Toast) Dismiss() {
+	t.timer.Stop()
+	t.visible.Set(false)
+}
+
+// Toast shows message over the view for d. Toasts still showing stack
+// downwards from the top-right corner, oldest first.
+func (s *Screen) Toast(message string, d time.Duration) *Toast {
+	t := &Toast{Message: message, visible: signals.New(true)}
+
+	// Drop the toasts that have gone; a new slice redraws the screen
+	var list []*Toast
+	for _, old := range s.toasts.Peek() {
+		if old.visible.Peek() {
+			list = append(list, old)
+		}
+	}
+	t.timer = time.AfterFunc(d, func() { t.visible.Set(false) })
+	s.toasts.Set(append(list, t))
+	return t
+}
+
+// drawToastsUnlocked draws the visible toasts over the back buffer. Reading
+// their signals makes the render effect redraw as they come and go.
+func (s *Screen) drawToastsUnlocked() {
+	y := 0
+	for _, t := range s.toasts.Get() {
+		if !t.visible.Get() {
+			continue
+		}
+		text := " " + t.Message + " "
+		x := s.Back.Width - stringWidth(text) - 1 // One column from the edge
+		if x < 0 {
+			x = 0
+		}
+		s.Back.SetString(x, y, text, ToastStyle)
+		y++
+	}
+}
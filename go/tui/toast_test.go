@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestToastDismissesItself(t *testing.T) {
+	s := newHeadlessScreen(20, 3, io.Discard)
+	Render(s, func() Renderable { return Template("hello") })
+	// The timer draws on its own goroutine
+	screen := func() string {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.Back.String()
+	}
+
+	first := s.Toast("saved", 20*time.Millisecond)
+	second := s.Toast("synced", time.Hour)
+	defer second.Dismiss()
+	if got := screen(); got != "hello        saved \n            synced \n\n" {
+		t.Errorf("Expected the toasts stacked in the top-right corner, got %q", got)
+	}
+	if !first.Visible().Peek() {
+		t.Fatalf("Expected the toast visible at first")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if first.Visible().Peek() {
+		t.Errorf("Expected the toast hidden after its duration")
+	}
+	if got := screen(); got != "hello       synced \n\n\n" {
+		t.Errorf("Expected the expired toast erased and the rest moved up, got %q", got)
+	}
+
+	second.Dismiss()
+	if got := screen(); got != "hello\n\n\n" {
+		t.Errorf("Expected a dismissed toast erased, got %q", got)
+	}
+}
@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"basement/basement"
+	"basement/signals"
+)
+
+// Toggle is a checkbox bound to a bool signal: it shows "[x]" or "[ ]" and
+// flips the signal on Space or Enter. Like List and TextInput, it implements
+// signals.Getter, resolving to its *LayoutNode view, so it can be placed
+// straight into a Template hole. Pass it the keys while it has focus.
+type Toggle struct {
+	value *signals.Signal[bool]
+	label string
+	views [2]*LayoutNode // Unchecked and checked, built once each
+}
+
+// NewToggle creates a checkbox showing and setting value
+func NewToggle(value *signals.Signal[bool]) *Toggle {
+	return &Toggle{value: value}
+}
+
+// WithLabel sets the text shown after the box
+func (t *Toggle) WithLabel(label string) *Toggle {
+	t.label = label
+	t.views = [2]*LayoutNode{}
+	return t
+}
+
+// Value returns the bound signal
+func (t *Toggle) Value() *signals.Signal[bool] {
+	return t.value
+}
+
+// HandleKey flips the value on Space or Enter. Returns true if the key was used.
+func (t *Toggle) HandleKey(ev KeyEvent) bool {
+	if ev.Key == KeyEnter || ev.Key == KeyChar && ev.Rune == ' ' && ev.Mod == ModNone {
+		t.value.Set(!t.value.Peek())
+		return true
+	}
+	return false
+}
+
+// View returns the checkbox and its label. Measure and Draw both resolve the
+// toggle, so each state's node is built once and handed out from then on.
+func (t *Toggle) View() *LayoutNode {
+	checked := 0
+	text := "[ ]"
+	if t.value.Get() {
+		checked, text = 1, "[x]"
+	}
+	if t.views[checked] != nil {
+		return t.views[checked]
+	}
+	if t.label != "" {
+		text += " " + t.label
+	}
+
+	block := basement.NewNode(basement.NodeBlock)
+	block.AddChild(&basement.Node{Type: basement.NodeText, Content: text})
+	root := basement.NewNode(basement.NodeRoot)
+	root.AddChild(block)
+	t.views[checked] = &LayoutNode{Width: Auto(), Height: Auto(), Content: root}
+	return t.views[checked]
+}
+
+// GetValue implements the Getter interface
+func (t *Toggle) GetValue() interface{} {
+	return t.View()
+}
@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"basement/signals"
+	"io"
+	"testing"
+)
+
+func TestToggleFlipsOnSpace(t *testing.T) {
+	done := signals.New(false)
+	toggle := NewToggle(done).WithLabel("Done")
+
+	s := newHeadlessScreen(12, 1, io.Discard)
+	Render(s, func() Renderable { return Template("%v", toggle) })
+	if got := s.Back.String(); got != "[ ] Done\n" {
+		t.Fatalf("Expected an empty box, got %q", got)
+	}
+
+	if !toggle.HandleKey(KeyEvent{Key: KeyChar, Rune: ' '}) || !done.Peek() {
+		t.Fatalf("Expected Space to set the signal")
+	}
+	if got := s.Back.String(); got != "[x] Done\n" {
+		t.Errorf("Expected the box checked after Space, got %q", got)
+	}
+
+	toggle.HandleKey(KeyEvent{Key: KeyEnter})
+	if done.Peek() || s.Back.String() != "[ ] Done\n" {
+		t.Errorf("Expected Enter to clear it again, got %v %q", done.Peek(), s.Back.String())
+	}
+	if toggle.HandleKey(KeyEvent{Key: KeyChar, Rune: 'x'}) {
+		t.Errorf("Expected other keys left to the caller")
+	}
+}
+
+func TestToggleInsideLayout(t *testing.T) {
+	toggle := NewToggle(signals.New(true))
+	got := RenderToString(Template("%v", Col("top", toggle, Box(toggle, true, 0))), 20, 6)
+	if want := "top\n[x]\n┌───┐\n│[x]│\n└───┘\n\n"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	s := newHeadlessScreen(10, 2, io.Discard)
+	Render(s, func() Renderable { return Template("%v", Col("top", toggle)) })
+	toggle.HandleKey(KeyEvent{Key: KeyChar, Rune: ' '})
+	if got := s.Back.String(); got != "top\n[ ]\n" {
+		t.Errorf("Expected the box cleared inside the column, got %q", got)
+	}
+}
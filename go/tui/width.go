@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// wideTail marks the cell covered by the right half of a double-width rune.
+// It is never written to the terminal; the glyph to its left fills it.
+const wideTail rune = -1
+
+// wideRanges lists the East Asian Wide and Fullwidth blocks (and emoji) that
+// terminals draw two columns wide
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},   // Hangul Jamo
+	{0x2E80, 0x303E},   // CJK Radicals .. CJK Symbols and Punctuation
+	{0x3041, 0x33FF},   // Hiragana .. CJK Compatibility
+	{0x3400, 0x4DBF},   // CJK Unified Ideographs Extension A
+	{0x4E00, 0x9FFF},   // CJK Unified Ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xAC00, 0xD7A3},   // Hangul Syllables
+	{0xF900, 0xFAFF},   // CJK Compatibility Ideographs
+	{0xFE30, 0xFE4F},   // CJK Compatibility Forms
+	{0xFF00, 0xFF60},   // Fullwidth Forms
+	{0xFFE0, 0xFFE6},   // Fullwidth Signs
+	{0x1F300, 0x1F64F}, // Pictographs, Emoticons
+	{0x1F900, 0x1F9FF}, // Supplemental Symbols and Pictographs
+	{0x20000, 0x3FFFD}, // CJK Extensions B and beyond
+}
+
+// runeWidth returns how many terminal columns r occupies: 2 for wide runes, else 1
+func runeWidth(r rune) int {
+	if r < 0x1100 {
+		return 1
+	}
+	for _, rg := range wideRanges {
+		if r < rg[0] {
+			break
+		}
+		if r <= rg[1] {
+			return 2
+		}
+	}
+	return 1
+}
+
+// stringWidth returns the number of terminal columns s occupies
+func stringWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		w += runeWidth(r)
+	}
+	return w
+}
+
+// truncateWidth cuts s to at most w columns, never splitting a wide rune
+func truncateWidth(s string, w int) string {
+	col := 0
+	for i, r := range s {
+		col += runeWidth(r)
+		if col > w {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// tabWidth is the distance between tab stops, see SetTabWidth
+var tabWidth int32 = 4
+
+// SetTabWidth sets how many columns apart tab stops are when drawing text
+// (default 4). Values below 1 are ignored.
+func SetTabWidth(width int) {
+	if width >= 1 {
+		atomic.StoreInt32(&tabWidth, int32(width))
+	}
+}
+
+// expandTabs replaces each tab in s with spaces up to the next tab stop.
+// Each line of s starts at column start, so text drawn at x with start x
+// lines its tabs up with the stops of what is drawn around it. Render passes
+// document columns, so the stops don't move as the view scrolls.
+func expandTabs(s string, start int) string {
+	if strings.IndexByte(s, '\t') < 0 {
+		return s
+	}
+	tw := int(atomic.LoadInt32(&tabWidth))
+	var b strings.Builder
+	col := start
+	for _, r := range s {
+		switch r {
+		case '\t':
+			// Columns left of the screen are negative, and so is col%tw
+			n := tw - ((col%tw)+tw)%tw
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+		case '\n':
+			b.WriteRune(r)
+			col = start
+		default:
+			b.WriteRune(r)
+			col += runeWidth(r)
+		}
+	}
+	return b.String()
+}